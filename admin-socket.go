@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// Serves administrative commands on connections accepted from l until it fails, such as when it's
+// closed. Each connection sends one command line, and receives "ok" or "error: " followed by the
+// reason, then any output, before the connection is closed. The commands are:
+//
+//	rules                 Show the rules in effect.
+//	set-rules <rules>     Replace the rules, in GO_LOG syntax.
+//	add-rules <rules>     Add rules in GO_LOG syntax that take precedence over existing rules.
+//	level                 Show the filter level of Default.
+//	level <level>         Set the filter level of Default.
+//	names                 List the names seen so far, and the level required to log them.
+//
+// See the golog command for a client.
+func ServeAdmin(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveAdminConn(conn)
+	}
+}
+
+// Listens on the Unix socket at path, replacing any stale socket file, and serves administrative
+// commands on it in the background. See ServeAdmin.
+func ListenAdminSocket(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode().Type() == fs.ModeSocket {
+		// The socket is only stale if nothing is listening on it anymore. Otherwise listening fails
+		// below, because the address is in use.
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			conn.Close()
+		} else if errors.Is(err, syscall.ECONNREFUSED) {
+			os.Remove(path)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	go ServeAdmin(l)
+	return l, nil
+}
+
+func serveAdminConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Minute))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return
+	}
+	w := bufio.NewWriter(conn)
+	defer w.Flush()
+	runAdminCommand(w, strings.TrimSpace(line))
+}
+
+// Runs an administrative command, and writes the response to w.
+func runAdminCommand(w io.Writer, line string) {
+	cmd, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	var output func(io.Writer)
+	var err error
+	switch cmd {
+	case "rules":
+		output = writeAdminRules
+	case "set-rules":
+		err = SetRules(arg)
+	case "add-rules":
+		var rules []InputRule
+		rules, err = parseRules(arg)
+		for _, r := range rules {
+			AddRule(r)
+		}
+	case "level":
+		if arg != "" {
+			var level Level
+			err = level.UnmarshalText([]byte(arg))
+			if err == nil {
+				defaultFilterLevel.Set(level)
+			}
+		}
+		output = func(w io.Writer) {
+			fmt.Fprintln(w, Default.currentFilterLevel())
+		}
+	case "names":
+		output = writeAdminNames
+	default:
+		err = fmt.Errorf("unknown command %q", cmd)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "error: %v\n", err)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+	if output != nil {
+		output(w)
+	}
+}
+
+func writeAdminRules(w io.Writer) {
+	for _, r := range Rules() {
+		fmt.Fprintln(w, describeRule(r))
+	}
+}
+
+func writeAdminNames(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	defer tw.Flush()
+	for _, sn := range ListSeenNames() {
+		fmt.Fprintf(tw, "%v\t%v\t%v\n", sn.Level, sn.Hits, strings.Join(sn.Names, " "))
+	}
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestAdminSocket(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	defer defaultFilterLevel.Set(defaultFilterLevel.Level())
+	path := filepath.Join(t.TempDir(), "log.sock")
+	l, err := ListenAdminSocket(path)
+	c.Assert(err, qt.IsNil)
+	defer func() { l.Close() }()
+	run := func(cmd string) string {
+		conn, err := net.Dial("unix", path)
+		c.Assert(err, qt.IsNil)
+		defer conn.Close()
+		_, err = fmt.Fprintln(conn, cmd)
+		c.Assert(err, qt.IsNil)
+		b, err := io.ReadAll(conn)
+		c.Assert(err, qt.IsNil)
+		return string(b)
+	}
+	c.Check(run("set-rules torrent=info"), qt.Equals, "ok\n")
+	c.Check(run("add-rules peer=debug,dht="), qt.Equals, "ok\n")
+	c.Check(run("rules"), qt.Equals, "ok\ntorrent=info\npeer=debug\ndht=\n")
+	c.Check(run("set-rules torrent=bogus"), qt.Matches, `error: parsing rule "torrent=bogus" at offset 0: .*\n`)
+	c.Check(run("rules"), qt.Equals, "ok\ntorrent=info\npeer=debug\ndht=\n")
+	c.Check(run("level debug"), qt.Equals, "ok\nDBG\n")
+	c.Check(Default.currentFilterLevel(), qt.Equals, Debug)
+	c.Check(run("level"), qt.Equals, "ok\nDBG\n")
+	c.Check(run("level loud"), qt.Matches, `error: unknown log level: "loud"\n`)
+	Default.WithNames("admin-test").Levelf(Debug, "hello")
+	c.Check(run("names"), qt.Matches, `(?s)ok\n.*DBG +\d+ +admin-test github.com/anacrolix/log .*`)
+	c.Check(run("frobnicate"), qt.Equals, "error: unknown command \"frobnicate\"\n")
+	// A socket that's still being listened on isn't replaced.
+	_, err = ListenAdminSocket(path)
+	c.Check(err, qt.IsNotNil)
+	c.Check(run("level"), qt.Equals, "ok\nDBG\n")
+	// A stale socket file is replaced.
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	l, err = ListenAdminSocket(path)
+	c.Assert(err, qt.IsNil)
+	c.Check(run("level"), qt.Equals, "ok\nDBG\n")
+}
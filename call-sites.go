@@ -0,0 +1,102 @@
+package log
+
+import (
+	"sync"
+)
+
+// Caches the names and rule results for messages logged from each call site, for a particular
+// sequence of Logger names. Filtered messages from a cached call site avoid formatting names,
+// applying rules and updating reports.
+type callSites struct {
+	m sync.Map // uintptr (program counter) -> *callSite
+}
+
+// Immutable once stored.
+type callSite struct {
+	origin Origin
+	// The flat view of origin.
+	names []string
+	seen  *namesSeen
+	// The rule set generation the rules result was determined with, or 0 if it isn't cached.
+	generation uint64
+	rules      ruleMatch
+}
+
+// The outcome of applying rules to a message.
+type ruleMatch struct {
+	// The rule that matched, or nil.
+	rule  InputRule
+	level Level
+	// The level for messages logged with NotSet from default level rules, or NotSet if none match.
+	defaultLevel Level
+}
+
+func (me ruleMatch) matched() bool {
+	return me.rule != nil
+}
+
+func (me *callSites) load(pc uintptr) *callSite {
+	if me == nil {
+		return nil
+	}
+	v, ok := me.m.Load(pc)
+	if !ok {
+		return nil
+	}
+	return v.(*callSite)
+}
+
+func (me *callSites) store(pc uintptr, cs *callSite) {
+	if me == nil {
+		return
+	}
+	me.m.Store(pc, cs)
+}
+
+// Returns the call site for a message logged from pc, and the result of applying the rules in
+// effect. msg may be the zero value if it hasn't been constructed.
+func (l loggerCore) callSiteAndRules(pc uintptr, msg Msg) (cs *callSite, match ruleMatch) {
+	rs := loadRuleSet()
+	cs = l.callSites.load(pc)
+	if cs != nil && rs.cacheable && cs.generation == rs.generation {
+		return cs, cs.rules
+	}
+	newCs := callSite{}
+	if cs != nil {
+		newCs.origin = cs.origin
+		newCs.names = cs.names
+		newCs.seen = cs.seen
+	} else {
+		newCs.origin = originFromLoc(l.names[:len(l.names):len(l.names)], getPcLoc(pc))
+		newCs.names = newCs.origin.Names()
+	}
+	input := RuleInput{
+		Names:  newCs.names,
+		Origin: newCs.origin,
+	}
+	if msg.MsgImpl != nil {
+		input.msg = msg.WithValues(l.values...)
+	}
+	index, level := rs.match(input)
+	if index >= 0 {
+		match = ruleMatch{rule: rs.rules[index], level: level}
+	}
+	match.defaultLevel = rs.defaultLevel(input)
+	if cs == nil {
+		var added bool
+		newCs.seen, added = reportedNames.put(newCs.names, newCs.origin, l.filterLevel, l.filterLevelVar)
+		if added {
+			reportExplanation(l.explanation(rs, input, index, level))
+		}
+	}
+	if rs.cacheable {
+		newCs.generation = rs.generation
+		newCs.rules = match
+	} else if cs != nil {
+		// The names are already cached.
+		return
+	}
+	cs = &newCs
+	l.callSites.store(pc, cs)
+	return
+}
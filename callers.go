@@ -38,16 +38,29 @@ func locFromPc(pc uintptr) Loc {
 	}
 }
 
+// Returns the function name without the package path, such as "(*Peer).onReadRequest". This
+// includes the receiver type for methods.
+func (l Loc) ShortFunction() string {
+	s := l.Function[strings.LastIndexByte(l.Function, '/')+1:]
+	return s[strings.IndexByte(s, '.')+1:]
+}
+
 var pcToLoc sync.Map
 
-func getMsgLogLoc(msg Msg) Loc {
+// Returns the program counter for where msg was logged from. msg should already skip to the
+// logging call, as seen from the caller of getMsgPc.
+func getMsgPc(msg Msg) uintptr {
 	var pc [1]uintptr
 	msg.Callers(1, pc[:])
-	locIf, ok := pcToLoc.Load(pc[0])
+	return pc[0]
+}
+
+func getPcLoc(pc uintptr) Loc {
+	locIf, ok := pcToLoc.Load(pc)
 	if ok {
 		return locIf.(Loc)
 	}
-	loc := locFromPc(pc[0])
-	pcToLoc.Store(pc[0], loc)
+	loc := locFromPc(pc)
+	pcToLoc.Store(pc, loc)
 	return loc
 }
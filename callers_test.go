@@ -42,3 +42,11 @@ func TestCallerLocs(t *testing.T) {
 	})
 	checkPcPackage(c, nestedPkgPc, "github.com/anacrolix/log/internal")
 }
+
+func TestShortFunction(t *testing.T) {
+	c := qt.New(t)
+	c.Check(locFromPc(globalFuncCaller()).ShortFunction(), qt.Equals, "globalFuncCaller")
+	c.Check(locFromPc(methodCaller{}.valueMethod()).ShortFunction(), qt.Equals, "methodCaller.valueMethod")
+	c.Check(locFromPc((*methodCaller).ptrMethod(nil)).ShortFunction(), qt.Equals, "(*methodCaller).ptrMethod")
+	c.Check(Loc{Function: "main.main"}.ShortFunction(), qt.Equals, "main")
+}
@@ -0,0 +1,65 @@
+// Command golog changes the logging rules of a running process through the admin socket that the
+// process serves when the GO_LOG_ADMIN_SOCKET environment variable is set. For example:
+//
+//	golog -socket /run/app/log.sock add-rules peer=debug@10m
+//
+// See github.com/anacrolix/log.ServeAdmin for the commands.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// This doesn't import the log package, since it would also serve the admin socket if the
+// environment variable is set.
+const envAdminSocket = "GO_LOG_ADMIN_SOCKET"
+
+func main() {
+	os.Exit(mainErr())
+}
+
+func mainErr() int {
+	socket := flag.String("socket", os.Getenv(envAdminSocket), "path to the admin socket")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-socket path] command [args...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || *socket == "" {
+		flag.Usage()
+		return 2
+	}
+	conn, err := net.Dial("unix", *socket)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error connecting to admin socket: %v\n", err)
+		return 1
+	}
+	defer conn.Close()
+	_, err = fmt.Fprintln(conn, strings.Join(flag.Args(), " "))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error sending command: %v\n", err)
+		return 1
+	}
+	r := bufio.NewReader(conn)
+	status, err := r.ReadString('\n')
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading response: %v\n", err)
+		return 1
+	}
+	if status != "ok\n" {
+		fmt.Fprint(os.Stderr, status)
+		return 1
+	}
+	_, err = io.Copy(os.Stdout, r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading response: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -4,16 +4,33 @@ import (
 	"context"
 )
 
-var loggerContextKey interface{} = (*Logger)(nil)
+var (
+	loggerContextKey      interface{} = (*Logger)(nil)
+	forcedLevelContextKey interface{} = (*Level)(nil)
+)
 
 func ContextWithLogger(ctx context.Context, logger Logger) context.Context {
 	return context.WithValue(ctx, loggerContextKey, logger)
 }
 
-func ContextLogger(ctx context.Context) Logger {
+// Returns the Logger added to ctx with ContextWithLogger, or Default. If ctx has a forced level from
+// ContextWithForcedLevel, it's applied to the returned Logger.
+func ContextLogger(ctx context.Context) (logger Logger) {
 	value := ctx.Value(loggerContextKey)
 	if value == nil {
-		return Default
+		logger = Default
+	} else {
+		logger = value.(Logger)
+	}
+	if level, ok := ctx.Value(forcedLevelContextKey).(Level); ok {
+		logger.forcedLevel = level
 	}
-	return value.(Logger)
+	return
+}
+
+// Returns a context that forces Loggers obtained from it with ContextLogger to log messages at the
+// given level or higher, regardless of rules and filter levels. This is for increasing verbosity
+// for a single request or operation. Messages below the level are filtered as usual.
+func ContextWithForcedLevel(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, forcedLevelContextKey, level)
 }
@@ -0,0 +1,95 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Serves the logging configuration as text, and allows changing it. It's intended to be mounted at
+// /debug/log. GET shows the rules, the Default Logger levels and handlers, and the names seen so
+// far. POST accepts form values: "rules" replaces the rules with the given value in GO_LOG syntax,
+// and "level" adds a rule that logs everything at that level or above for the duration given by
+// "for", which defaults to 10 minutes. The status is shown after a successful POST.
+type DebugHttpHandler struct{}
+
+var _ http.Handler = DebugHttpHandler{}
+
+func (me DebugHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost:
+		err := me.handlePost(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writeDebugStatus(w)
+}
+
+func (me DebugHttpHandler) handlePost(r *http.Request) error {
+	err := r.ParseForm()
+	if err != nil {
+		return err
+	}
+	if r.PostForm.Has("rules") {
+		err = SetRules(r.PostForm.Get("rules"))
+		if err != nil {
+			return fmt.Errorf("setting rules: %w", err)
+		}
+	}
+	if r.PostForm.Has("level") {
+		var level Level
+		err = level.UnmarshalText([]byte(r.PostForm.Get("level")))
+		if err != nil {
+			return err
+		}
+		d := 10 * time.Minute
+		if r.PostForm.Has("for") {
+			d, err = time.ParseDuration(r.PostForm.Get("for"))
+			if err != nil {
+				return fmt.Errorf("parsing duration: %w", err)
+			}
+		}
+		AddRuleFor(MinLevel(MatchAny(), level), d)
+	}
+	return nil
+}
+
+func writeDebugStatus(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintf(tw, "rules:\n")
+	for i, r := range Rules() {
+		fmt.Fprintf(tw, "  %v\t%v", i, describeRule(r))
+		if er, ok := ruleAs[*expiringRule](r); ok {
+			fmt.Fprintf(tw, " (until %v)", er.until.Format(time.RFC3339))
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintf(tw, "default logger:\n")
+	fmt.Fprintf(tw, "  filter level\t%v\n", Default.currentFilterLevel())
+	fmt.Fprintf(tw, "  default level\t%v\n", Default.defaultLevel)
+	fmt.Fprintf(tw, "  stack trace level\t%v\n", Default.stackTraceLevel)
+	for _, h := range Default.Handlers {
+		fmt.Fprintf(tw, "  handler\t%T\n", h)
+	}
+	fmt.Fprintf(tw, "seen names:\n")
+	fmt.Fprintf(tw, "  level\thits\tlast handled\tnames\n")
+	for _, sn := range ListSeenNames() {
+		lastHandled := "never"
+		if !sn.LastHandled.IsZero() {
+			lastHandled = sn.LastHandled.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "  %v\t%v\t%v\t%v\n", sn.Level, sn.Hits, lastHandled, strings.Join(sn.Names, " "))
+	}
+}
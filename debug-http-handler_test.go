@@ -0,0 +1,41 @@
+package log
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDebugHttpHandler(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	s := httptest.NewServer(DebugHttpHandler{})
+	defer s.Close()
+	get := func() string {
+		resp, err := http.Get(s.URL)
+		c.Assert(err, qt.IsNil)
+		defer resp.Body.Close()
+		c.Assert(resp.StatusCode, qt.Equals, http.StatusOK)
+		b, err := io.ReadAll(resp.Body)
+		c.Assert(err, qt.IsNil)
+		return string(b)
+	}
+	post := func(form url.Values) int {
+		resp, err := http.PostForm(s.URL, form)
+		c.Assert(err, qt.IsNil)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	c.Check(get(), qt.Matches, `(?s).*filter level +WRN\n.*`)
+	c.Check(post(url.Values{"rules": {"torrent=debug"}}), qt.Equals, http.StatusOK)
+	c.Check(get(), qt.Matches, `(?s).*0 +torrent=debug\n.*`)
+	c.Check(post(url.Values{"rules": {"~("}}), qt.Equals, http.StatusBadRequest)
+	c.Check(Rules(), qt.HasLen, 1)
+	c.Check(post(url.Values{"level": {"debug"}, "for": {"1h"}}), qt.Equals, http.StatusOK)
+	c.Check(get(), qt.Matches, `(?s).*1 +\*=dbg@1h0m0s \(until .*`)
+	c.Check(post(url.Values{"level": {"bogus"}}), qt.Equals, http.StatusBadRequest)
+}
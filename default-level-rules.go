@@ -0,0 +1,66 @@
+package log
+
+import (
+	"errors"
+	"strings"
+)
+
+// The suffix of a filter that makes a rule set the default level, rather than the minimum level.
+const defaultLevelSuffix = ":default"
+
+// A rule that sets the level used for messages logged with NotSet that match filter, instead of the
+// Logger's default level. It doesn't match for the purposes of filtering.
+type defaultLevelRule struct {
+	filterRule
+}
+
+// A rule that makes messages matching filter that are logged with NotSet have the given level. This
+// is the "filter:default=level" rule form.
+func DefaultLevel(filter Filter, level Level) InputRule {
+	return defaultLevelRule{filterRule{
+		filter: filter,
+		level:  level,
+	}}
+}
+
+func (r defaultLevelRule) Apply(RuleInput) (_ Level, matched bool) {
+	return
+}
+
+func (r defaultLevelRule) String() string {
+	if r.text != "" {
+		return r.text
+	}
+	var rules []string
+	for _, conj := range r.filter.dnf() {
+		rules = append(rules, formatConjunction(conj)+defaultLevelSuffix+"="+strings.ToLower(r.level.LogString()))
+	}
+	return strings.Join(rules, ",")
+}
+
+// Parses the filter and level of a rule with defaultLevelSuffix. text is the full text of the rule.
+func parseDefaultLevelRule(text, filterText, levelText string) (InputRule, error) {
+	filter, err := parseFilter(filterText)
+	if err != nil {
+		return nil, err
+	}
+	level, ok, err := levelFromString(levelText)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || level.isNotSet() || level == Disabled {
+		return nil, errors.New("default level rules require a level")
+	}
+	return defaultLevelRule{filterRule{text, filter, level}}, nil
+}
+
+// Returns the default level from the last matching default level rule, or NotSet if none match.
+func (rs *ruleSet) defaultLevel(input RuleInput) Level {
+	for i := len(rs.rules) - 1; i >= 0; i-- {
+		r, ok := ruleAs[defaultLevelRule](rs.rules[i])
+		if ok && r.filter.Matches(input) {
+			return r.level
+		}
+	}
+	return NotSet
+}
@@ -9,16 +9,37 @@ When not used to replace std log, the import should use the package name "analog
 
 # Names
 
-Each Logger has a sequence of names that are used for filtering and context. Names are commonly attached as Loggers are passed into code of deeper context. The full import path of the package where a message is generated, and the short source file name and line number are added as the last 2 names for each message (applying any [Msg.Skip] in finding the right frame) when filtering is applied. The names are included at the end of each logging line.
+Each Logger has a sequence of names that are used for filtering and context. Names are commonly attached as Loggers are passed into code of deeper context. The full import path of the package where a message is generated, the function name without the package path (such as "(*Peer).onReadRequest"), and the short source file name and line number are added as the last 3 names for each message (applying any [Msg.Skip] in finding the right frame) when filtering is applied. The names are included at the end of each logging line.
+
+# Levels
+
+The predefined levels from lowest to highest are [Debug], [Info], [Warning], [Error] and [Critical]. [NotSet] is used for messages logged without a level, which get the Logger's default level. More levels can be added with [RegisterLevel], and are then accepted everywhere the predefined levels are, including rules, formatters and log/slog:
+
+	var Notice = analog.RegisterLevel(analog.LevelDef{Above: analog.Info, ShortName: "NTC", LongName: "notice", SlogLevel: slog.LevelInfo + 2})
+
+Levels from log/slog are mapped to the registered level with the highest slog level that doesn't exceed them. If the mapping isn't exact, the original slog level is added to the message as a value with the key [SlogLevelKey], and is restored by [SlogHandlerAsHandler].
 
 # Rules
 
 A sequence of rules are parsed from the environment variable with the key of [EnvRules]. Rules are separated by ",". Each rule is a substring of a log message name that or "*" to match any name. If there is no "=" in the rule, then all messages that match will be logged. If there is a "=", then a message must have the level following the "=", as parsed by [Level.UnmarshalText] or higher to be logged. Each rule is checked in order, and the last match takes precedence. This helps when you want to chain new rules on existing ones, you can always append to the end to override earlier rules.
 
 	GO_LOG := "" | rule ("," rule)*
-	rule := filter ("=" level) | ""
-	filter := name | "*"
+	rule := filter ("=" level)? ("%" sample)? ("@" option)* | filter ":default=" level ("@" duration)* | ""
+	filter := "*" | term ("+" term)*
+	term := "!"? (substring | glob | "~" regexp | component ":" value | "{" key ("=" value)? "}")
+	component := "name" | "pkg" | "func" | "file"
+	value := exact | glob | "~" regexp
 	level := "all", "debug" | "info" | "warn" | "err" | "crit" | see [Level.UnmarshalText]
+	sample := count | fraction
+	option := duration | count "/" duration
+
+A filter matches a message if every one of its terms matches. A term matches if any of the message names match it, or if none do when the term is prefixed with "!". A plain term matches names that contain it as a substring. A term containing "*" or "?" is a glob that must match an entire name, where "*" matches any sequence of characters including "/", and "?" matches a single character. A term starting with "~" is a regular expression in the syntax of [regexp], and is unanchored unless it uses "^" and "$". A term prefixed with a component and ":" matches only that part of the names, rather than any name: "name" for the names of the Logger, "pkg" for the package import path, "func" for the function, and "file" for the short file name, or the file name and line if the value contains ":". Unlike plain terms, the value must match the component exactly, or as a glob or regular expression. The components are available to handlers as [Record.Origin]. A term in braces matches the values of a message instead of its names, such as those added with [Msg.With] or [Logger.WithValues]. "{key}" matches if a value was added with a key that formats as key, and "{key=value}" additionally requires the value to format exactly as value, or match it as a glob or regular expression. Since "," "+" "=" "%" and "@" delimit rules, terms can't contain them, except inside braces.
+
+A rule with a sample keeps 1 in count, or the given fraction, of the messages it allows. Sampling is random unless a seed is given with [SetSamplingSeed] or the environment variable with the key of [EnvSamplingSeed], in which case the same messages are kept from each call site every time. [Logger.WithSampling] does the same for all messages through a Logger. Kept messages have the fraction kept added as a value with the key [SampleRateKey].
+
+A rule with ":default" after the filter doesn't filter messages. Instead it sets the level of messages that match and are logged without a level, such as with [Logger.Print], in place of the Logger's default level, which [EnvDefaultLevel] sets for [Default]. The resulting level is then filtered as usual. [DefaultLevel] makes these rules in code.
+
+A rule with a duration option, as parsed by [time.ParseDuration], expires and is removed that long after it's parsed. [AddRuleFor] does the same for rules added in code. A rule with a rate option, such as "50/s" or "10/5m", allows at most that many messages within each period for each permutation of names that it matches. Messages over the rate are dropped, and a summary of how many were suppressed is logged through the same Logger after the period. Rules that fail to parse are reported as errors rather than matching nothing. If the rules or any other configuration from the environment are invalid when the package is initialized, they are ignored in favour of the defaults, and a [Critical] message naming the invalid rule and its offset is logged to [Default]. The error is available from [ConfigError]. Set the environment variable with the key [EnvStrictConfig] to panic instead.
 
 Some examples:
 
@@ -34,11 +55,51 @@ Some examples:
 
     Handle messages at the info level or greater if they have a name containing "something".
 
-If no rule matches, the [Logger]'s filter level is checked. The [Default] filter level is [Warning]. This means only messages with the level of [Warning] or higher will be logged, unless overridden by the specific Logger in use, or a rule from the environment matches.
+  - GO_LOG=github.com/anacrolix/torrent/*=debug,!webseed+~^peer_protocol.*$=info
+
+    Handle debug messages from packages under github.com/anacrolix/torrent, except require info level for messages with a name starting with "peer_protocol", unless they also have a name containing "webseed".
+
+  - GO_LOG=pkg:github.com/anacrolix/torrent=debug,func:(*Peer).onReadRequest=info
+
+    Handle debug messages from the package github.com/anacrolix/torrent, but not its subpackages, and info messages from the method onReadRequest on *Peer.
+
+  - GO_LOG=torrent=debug@10m
+
+    Handle debug messages with a name containing "torrent" for the next 10 minutes.
+
+  - GO_LOG=peerconn=info@50/s
+
+    Handle info messages with a name containing "peerconn", but no more than 50 per second from each call site and Logger names.
+
+  - GO_LOG=dht=debug%100
+
+    Handle 1 in 100 debug messages with a name containing "dht".
+
+  - GO_LOG=dht:default=debug,torrent:default=info
+
+    Give messages logged without a level the debug level if they have a name containing "dht", and the info level if they have a name containing "torrent".
+
+  - GO_LOG=torrent+{peer=1.2.3.4:6881}=debug
+
+    Handle debug messages with a name containing "torrent" that have a value with key "peer" that formats as "1.2.3.4:6881".
+
+The rules can be replaced at runtime with [SetRules], which takes the same syntax, or extended in code with [AddRule], which takes a [Rule] function of the message names, or any [InputRule] that also considers message values and origin. Rules can be built in code from a [Filter] with [MinLevel], where filters are made with constructors such as [NameContains], [Package], [Function] and [FileLine], and combined with [And], [Or] and [Not]. Their String method renders them in the syntax above:
+
+	log.AddRule(log.MinLevel(log.And(log.Package("github.com/anacrolix/dht/v2"), log.Not(log.NameContains("announce"))), log.Debug))
+
+If the environment variable with the key [EnvRulesFile] is set, rules are also read from the file it names, with one or more rules per line in the same syntax. Blank lines and lines starting with "#" are ignored. The file's rules take precedence over those from [EnvRules], and are reloaded on SIGHUP or when the file's modification time changes. If the file fails to load, the previous rules are kept and the error is logged to [Default]. [SetRules] keeps the file's rules, but gives the new rules precedence over them.
+
+Changes are safe to make while other goroutines are logging. [DebugHttpHandler] allows viewing and changing them over HTTP. If the environment variable with the key [EnvAdminSocket] is set, the rules and the [Default] filter level can also be viewed and changed through a Unix socket at that path, using the golog command. See [ServeAdmin]. [InstallSignalHandlers] allows lowering the [Default] filter level a step at a time with SIGUSR1, and restoring it with SIGUSR2.
+
+If no rule matches, the [Logger]'s filter level is checked. The [Default] filter level is [Warning]. This means only messages with the level of [Warning] or higher will be logged, unless overridden by the specific Logger in use, or a rule from the environment matches. [Logger.WithFilterLeveler] makes a Logger and those derived from it take their filter level from a [LevelVar], so it can be changed while they're in use.
+
+# Stack traces
+
+[Logger.WithStackTraceLevel] makes a Logger capture the stack where messages at or above a level are logged, up to a limited depth. The environment variable with the key [EnvStackTraceLevel] does the same for [Default]. The stack is available to handlers as [Record.Stack], and is included by the standard formatters, and by [SlogHandlerAsHandler] with the key [StackTraceKey].
 
 # Rule reporting
 
-If the environment variable with the key [EnvReportRules] is not the empty string, each message logged with a previously unseen permutation of names will output a message to a standard library logger with the minimum level required to log that permutation. The message itself is then handled as usual. The same permutation will not be reported on again. This is useful to determine what logging names are in use, and to debug their reporting level thresholds.
+If the environment variable with the key [EnvReportRules] is not the empty string, each message logged with a previously unseen permutation of names will be reported to [ReportRulesHandler] with an [Explanation] of the minimum level required to log that permutation, including which rule determined it. The message itself is then handled as usual. The same permutation will not be reported on again. This is useful to determine what logging names are in use, and to debug their reporting level thresholds. [Explain] provides the same information on demand, and [ListSeenNames] lists every permutation of names seen so far, with usage counts.
 
 [Python logging module]: https://docs.python.org/3/library/logging.html
 */
@@ -5,5 +5,16 @@ const (
 	EnvTimeFormat   = "GO_LOG_TIME_FMT"
 	EnvDefaultLevel = "GO_LOG_DEFAULT_LEVEL"
 	EnvReportRules  = "GO_LOG_REPORT_RULES"
+	EnvSamplingSeed = "GO_LOG_SAMPLING_SEED"
+	// A file of rules that are merged after those from EnvRules, and reloaded on SIGHUP or when
+	// it's modified.
+	EnvRulesFile = "GO_LOG_FILE"
+	// If set, invalid configuration in the environment panics during initialization, instead of
+	// being ignored. See ConfigError.
+	EnvStrictConfig = "GO_LOG_STRICT_CONFIG"
+	// If set, administrative commands are served on a Unix socket at this path. See ServeAdmin.
+	EnvAdminSocket = "GO_LOG_ADMIN_SOCKET"
+	// The level at which Default captures stack traces. See Logger.WithStackTraceLevel.
+	EnvStackTraceLevel = "GO_LOG_STACK_LEVEL"
 	//EnvDefaultFormatter = "GO_LOG_FORMATTER"
 )
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"strings"
+	"time"
+)
+
+// The clock used to expire rules. It's replaced in tests.
+var timeNow = time.Now
+
+// A rule that is removed once its time is up. It's a pointer so it can be identified for removal.
+type expiringRule struct {
+	InputRule
+	until time.Time
+	// The "@" option the rule was parsed with, if it was.
+	option string
+}
+
+func (me *expiringRule) unwrapRule() InputRule {
+	return me.InputRule
+}
+
+func (me *expiringRule) namesOnly() bool {
+	return ruleIsNamesOnly(me.InputRule)
+}
+
+// Renders the rule with the time remaining in place of the option it was parsed with, so that
+// parsing the result gives a rule that expires at about the same time.
+func (me *expiringRule) String() string {
+	s := describeRule(me.InputRule)
+	if i := strings.LastIndex(s, "@"+me.option); me.option != "" && i >= 0 {
+		s = s[:i] + s[i+len("@"+me.option):]
+	}
+	remaining := me.until.Sub(timeNow()).Round(time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	// Built filters can render as several rules, and each needs the option.
+	rules := strings.Split(s, ",")
+	for i := range rules {
+		rules[i] += "@" + remaining.String()
+	}
+	return strings.Join(rules, ",")
+}
+
+func (me *expiringRule) expired(now time.Time) bool {
+	return !now.Before(me.until)
+}
+
+// Returns the rule wrapped so it expires after d.
+func expireRuleAfter(r InputRule, d time.Duration) *expiringRule {
+	return &expiringRule{InputRule: r, until: timeNow().Add(d)}
+}
+
+// Adds a rule that takes precedence over all the rules already in effect, and that is removed
+// after d.
+func AddRuleFor(r InputRule, d time.Duration) {
+	AddRule(expireRuleAfter(r, d))
+}
+
+// Returns the earliest time a rule expires, or the zero Time.
+func rulesExpiry(rules []InputRule) (earliest time.Time) {
+	for _, r := range rules {
+		er, ok := ruleAs[*expiringRule](r)
+		if ok && (earliest.IsZero() || er.until.Before(earliest)) {
+			earliest = er.until
+		}
+	}
+	return
+}
+
+func removeExpiredRules(now time.Time) {
+	removeRules(func(r InputRule) bool {
+		er, ok := ruleAs[*expiringRule](r)
+		return ok && er.expired(now)
+	})
+}
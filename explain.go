@@ -0,0 +1,72 @@
+package log
+
+import (
+	"fmt"
+)
+
+// Describes how the minimum level to log a message with particular names was determined.
+type Explanation struct {
+	Names []string
+	// The index in Rules of the rule that matched, or -1 if none did.
+	RuleIndex int
+	// The rule that matched in GO_LOG syntax, if it has one. Otherwise it's a description of the
+	// rule.
+	Rule string
+	// The minimum level for a message to be logged.
+	Level Level
+	// No rule matched, so the Logger filter level applies.
+	UsedFilterLevel bool
+	// The level given to messages logged with NotSet, from a default level rule or the Logger.
+	DefaultLevel Level
+}
+
+func (e Explanation) String() (s string) {
+	if e.UsedFilterLevel {
+		s = fmt.Sprintf("no rule matched, using filter level %v", e.Level)
+	} else {
+		s = fmt.Sprintf("rule %v (%v) requires level %v", e.RuleIndex, e.Rule, e.Level)
+	}
+	return s + fmt.Sprintf(", default level %v", e.DefaultLevel)
+}
+
+// Explains how the level required to log a message with the given names is determined for the
+// Default Logger. names should include the package, function and file location names that are
+// added to messages, as returned by Origin.Names.
+func Explain(names []string) Explanation {
+	return Default.Explain(names)
+}
+
+// Explains how the level required to log a message with the given names is determined for this
+// Logger. Rules that depend on message values are applied as though the message has no values.
+func (l loggerCore) Explain(names []string) Explanation {
+	rs := loadRuleSet()
+	input := RuleInput{
+		Names:  names,
+		Origin: originFromNames(names),
+	}
+	index, level := rs.match(input)
+	return l.explanation(rs, input, index, level)
+}
+
+func (l loggerCore) explanation(rs *ruleSet, input RuleInput, index int, level Level) Explanation {
+	e := Explanation{
+		Names:        input.Names,
+		RuleIndex:    index,
+		DefaultLevel: l.resolveLevel(NotSet, ruleMatch{defaultLevel: rs.defaultLevel(input)}),
+	}
+	if index < 0 {
+		e.Level = l.currentFilterLevel()
+		e.UsedFilterLevel = true
+		return e
+	}
+	e.Level = level
+	e.Rule = describeRule(rs.rules[index])
+	return e
+}
+
+func describeRule(r InputRule) string {
+	if s, ok := r.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", r)
+}
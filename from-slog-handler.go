@@ -14,12 +14,16 @@ type SlogHandlerAsHandler struct {
 }
 
 func (me SlogHandlerAsHandler) Handle(r Record) {
-	slogLevel, ok := toSlogLevel(r.Level)
-	if !ok {
+	if r.Level.isNotSet() {
 		// Might be a bit harsh to panic here. Seems to happen if you use default logging and a
 		// default level isn't set. We're afraid to lose messages and not be able to work out why.
 		panic(r.Level)
 	}
+	slogLevel, _ := toSlogLevel(r.Level)
+	originalSlogLevel, hasOriginal := recordSlogLevel(r)
+	if hasOriginal {
+		slogLevel = originalSlogLevel
+	}
 	if !me.SlogHandler.Enabled(context.TODO(), slogLevel) {
 		return
 	}
@@ -31,8 +35,14 @@ func (me SlogHandlerAsHandler) Handle(r Record) {
 		anyNames = append(anyNames, name)
 	}
 	slogRecord.AddAttrs(slog.Any("names", r.Names))
+	if len(r.Stack) != 0 {
+		slogRecord.AddAttrs(slog.Any(StackTraceKey, r.Stack.Strings()))
+	}
 	r.Values(func(value interface{}) (more bool) {
 		if item, ok := value.(item); ok {
+			if hasOriginal && item.key == SlogLevelKey {
+				return true
+			}
 			slogRecord.AddAttrs(slog.Any(fmt.Sprint(item.key), item.value))
 			return true
 		}
@@ -45,3 +55,22 @@ func (me SlogHandlerAsHandler) Handle(r Record) {
 }
 
 var _ Handler = SlogHandlerAsHandler{}
+
+// Returns the slog.Level the record had when it came from log/slog, if it couldn't be represented
+// exactly and the level hasn't been changed since.
+func recordSlogLevel(r Record) (sl slog.Level, ok bool) {
+	r.Values(func(value interface{}) bool {
+		item, isItem := value.(item)
+		if !isItem || item.key != SlogLevelKey {
+			return true
+		}
+		switch v := item.value.(type) {
+		case slog.Level:
+			sl, ok = v, true
+		case slog.Value:
+			sl, ok = v.Any().(slog.Level)
+		}
+		return !ok
+	})
+	return sl, ok && fromSlogLevel(sl) == r.Level
+}
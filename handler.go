@@ -7,5 +7,10 @@ type Handler interface {
 type Record struct {
 	Msg
 	Level Level
+	// The flat view of Origin, for compatibility and simple formatting.
 	Names []string
+	// The names of the message, by their kind.
+	Origin Origin
+	// The stack where the message was logged, if the Logger captures one at this level.
+	Stack StackTrace
 }
@@ -1,25 +1,93 @@
 package log
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
 )
 
+var (
+	configError error
+	// The filter level of Default, and Loggers derived from it.
+	defaultFilterLevel LevelVar
+)
+
+// Returns the error from configuring the package from the environment, or nil if there was none.
+// Invalid configuration is ignored, and the defaults are used in its place, unless the environment
+// variable with the key [EnvStrictConfig] is set, in which case initialization panics.
+func ConfigError() error {
+	return configError
+}
+
 func init() {
-	var err error
-	rules, err = parseEnvRules()
-	if err != nil {
-		panic(err)
-	}
 	// The default formatter should be parsed here when it is implemented.
 	Default = loggerCore{
 		nonZero: true,
 		// This is the level if no rules apply, unless overridden in this logger, or any derived
 		// loggers.
-		filterLevel: Warning,
-		Handlers:    []Handler{DefaultHandler},
+		filterLevel:    Warning,
+		filterLevelVar: &defaultFilterLevel,
+		Handlers:       []Handler{DefaultHandler},
+		callSites:      new(callSites),
 	}.asLogger()
-	Default.defaultLevel, _, err = levelFromString(os.Getenv(EnvDefaultLevel))
+	defaultFilterLevel.Set(Warning)
+	errs := configureFromEnv(os.Getenv)
+	configError = errors.Join(errs...)
+	if configError == nil {
+		return
+	}
+	if os.Getenv(EnvStrictConfig) != "" {
+		panic(configError)
+	}
+	for _, err := range errs {
+		Default.Levelf(Critical, "ignoring invalid configuration: %v", err)
+	}
+}
+
+// Applies the configuration from the environment variables returned by getenv. Each variable that
+// is invalid is left at its default, and an error is returned for it.
+func configureFromEnv(getenv func(string) string) (errs []error) {
+	envError := func(key string, err error) {
+		errs = append(errs, fmt.Errorf("$%v: %w", key, err))
+	}
+	rules, err := parseRules(getenv(EnvRules))
 	if err != nil {
-		panic(err)
+		envError(EnvRules, err)
+	}
+	activeRules.Store(newRuleSet(rules))
+	Default.defaultLevel, _, err = levelFromString(getenv(EnvDefaultLevel))
+	if err != nil {
+		envError(EnvDefaultLevel, err)
+	}
+	Default.stackTraceLevel, _, err = levelFromString(getenv(EnvStackTraceLevel))
+	if err != nil {
+		envError(EnvStackTraceLevel, err)
+	}
+	if seedStr := getenv(EnvSamplingSeed); seedStr != "" {
+		seed, err := strconv.ParseUint(seedStr, 0, 64)
+		if err != nil {
+			envError(EnvSamplingSeed, err)
+		} else {
+			SetSamplingSeed(seed)
+		}
+	}
+	if path := getenv(EnvRulesFile); path != "" {
+		rf := &rulesFile{path: path}
+		err = rf.load()
+		if err != nil {
+			envError(EnvRulesFile, err)
+		}
+		// Watch the file even if it failed to load, so that it can be fixed.
+		reload := make(chan os.Signal, 1)
+		notifyReloadRulesFile(reload)
+		go rf.watch(reload)
+	}
+	if path := getenv(EnvAdminSocket); path != "" {
+		_, err = ListenAdminSocket(path)
+		if err != nil {
+			envError(EnvAdminSocket, err)
+		}
 	}
+	return
 }
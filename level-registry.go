@@ -0,0 +1,169 @@
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Describes a Level to add with RegisterLevel.
+type LevelDef struct {
+	// The new level is ordered immediately above this level, and below any level that was already
+	// above it.
+	Above Level
+	// The name used by formatters, conventionally 3 upper case letters, such as "TRC".
+	ShortName string
+	// The full name, such as "trace".
+	LongName string
+	// Other names accepted when parsing a level, such as in rules. The short and long names are
+	// always accepted. Names are matched without regard to case.
+	Aliases []string
+	// The equivalent level for log/slog. It must be strictly between the slog levels of the
+	// registered levels either side of the new level, so that the mapping keeps the order.
+	SlogLevel slog.Level
+}
+
+type registeredLevel struct {
+	level Level
+	def   LevelDef
+}
+
+// An immutable set of the known levels. It's replaced to register new levels, so lookups don't
+// need locking.
+type levelRegistry struct {
+	// Sorted by rank.
+	levels []registeredLevel
+	byRank map[int]registeredLevel
+	byName map[string]Level
+}
+
+// Levels that have names, but aren't registered, because they can't be used for messages.
+var specialLevels = []registeredLevel{
+	{Never, LevelDef{ShortName: "NEVER", LongName: "never"}},
+	{Disabled, LevelDef{ShortName: "DISABLED", LongName: "disabled", Aliases: []string{"off"}}},
+}
+
+var (
+	registeredLevels atomic.Pointer[levelRegistry]
+	registerLevels   sync.Mutex
+	// In effect until a level is registered. It's initialized with the package variables so levels
+	// can be parsed during init. NotSet is included for its names, but it doesn't have a slog
+	// equivalent.
+	predefinedLevels = newLevelRegistry([]registeredLevel{
+		{NotSet, LevelDef{ShortName: "NIL", LongName: "notset", Aliases: []string{"unset", "all", "*"}}},
+		{Debug, LevelDef{ShortName: "DBG", LongName: "debug", SlogLevel: slog.LevelDebug}},
+		{Info, LevelDef{ShortName: "INF", LongName: "info", SlogLevel: slog.LevelInfo}},
+		{Warning, LevelDef{ShortName: "WRN", LongName: "warning", Aliases: []string{"warn"}, SlogLevel: slog.LevelWarn}},
+		{Error, LevelDef{ShortName: "ERR", LongName: "error", SlogLevel: slog.LevelError}},
+		{Critical, LevelDef{ShortName: "CRT", LongName: "critical", Aliases: []string{"crit"}, SlogLevel: slog.LevelError + 1}},
+	})
+)
+
+func loadLevels() *levelRegistry {
+	if reg := registeredLevels.Load(); reg != nil {
+		return reg
+	}
+	return predefinedLevels
+}
+
+func newLevelRegistry(registered []registeredLevel) *levelRegistry {
+	reg := &levelRegistry{
+		levels: registered,
+		byRank: make(map[int]registeredLevel, len(registered)),
+		byName: make(map[string]Level),
+	}
+	sort.Slice(reg.levels, func(i, j int) bool {
+		return reg.levels[i].level.rank < reg.levels[j].level.rank
+	})
+	for _, rl := range append(registered[:len(registered):len(registered)], specialLevels...) {
+		reg.byRank[rl.level.rank] = rl
+		for _, name := range rl.def.names() {
+			reg.byName[name] = rl.level
+		}
+	}
+	return reg
+}
+
+// The names a level can be parsed from, in lower case.
+func (def LevelDef) names() (ret []string) {
+	for _, name := range append([]string{def.ShortName, def.LongName}, def.Aliases...) {
+		if name != "" {
+			ret = append(ret, strings.ToLower(name))
+		}
+	}
+	return
+}
+
+// Adds a new Level ordered immediately above def.Above. The new level can be used in rules, by
+// formatters and with log/slog like the predefined levels. Levels should be registered during
+// initialization, such as in a package variable declaration, before they're used. This panics if a
+// name is already in use, def.Above isn't registered, the slog level is out of order, or there's no
+// room for another level at that position.
+func RegisterLevel(def LevelDef) Level {
+	registerLevels.Lock()
+	defer registerLevels.Unlock()
+	old := loadLevels()
+	if def.ShortName == "" {
+		panic("level must have a short name")
+	}
+	for _, name := range def.names() {
+		if _, ok := old.byName[name]; ok {
+			panic(fmt.Sprintf("level name %q is already registered", name))
+		}
+	}
+	if def.Above == Never || def.Above.rank >= Disabled.rank {
+		panic(fmt.Sprintf("can't register a level above %v", def.Above))
+	}
+	if _, ok := old.byRank[def.Above.rank]; !ok {
+		panic(fmt.Sprintf("level %v is not registered", def.Above))
+	}
+	next := Disabled.rank
+	var below, above *registeredLevel
+	for i := range old.levels {
+		rl := &old.levels[i]
+		if rl.level.rank > def.Above.rank {
+			next = rl.level.rank
+			above = rl
+			break
+		}
+		// NotSet has no slog equivalent.
+		if rl.level != NotSet {
+			below = rl
+		}
+	}
+	if below != nil && def.SlogLevel <= below.def.SlogLevel || above != nil && def.SlogLevel >= above.def.SlogLevel {
+		panic(fmt.Sprintf("slog level %v must be between those of the neighbouring levels", def.SlogLevel))
+	}
+	rank := def.Above.rank + (next-def.Above.rank)/2
+	if rank == def.Above.rank {
+		panic(fmt.Sprintf("no room to register a level above %v", def.Above))
+	}
+	level := Level{rank}
+	registeredLevels.Store(newLevelRegistry(append(
+		old.levels[:len(old.levels):len(old.levels)],
+		registeredLevel{level, def},
+	)))
+	return level
+}
+
+// Returns the registered definition of level.
+func lookupLevel(level Level) (def LevelDef, ok bool) {
+	rl, ok := loadLevels().byRank[level.rank]
+	return rl.def, ok
+}
+
+// Returns the next registered level below level that lets more messages through, or level if
+// there isn't one.
+func levelBelow(level Level) Level {
+	ret := level
+	for _, rl := range loadLevels().levels {
+		if rl.level.rank >= level.rank {
+			break
+		}
+		ret = rl.level
+	}
+	return ret
+}
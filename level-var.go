@@ -0,0 +1,37 @@
+package log
+
+import (
+	"sync/atomic"
+)
+
+// A Level that can be changed while Loggers are using it, like slog.LevelVar. The zero value is
+// NotSet. See Logger.WithFilterLeveler.
+type LevelVar struct {
+	rank atomic.Int64
+}
+
+func (me *LevelVar) Level() Level {
+	return Level{int(me.rank.Load())}
+}
+
+func (me *LevelVar) Set(level Level) {
+	me.rank.Store(int64(level.rank))
+}
+
+func (me *LevelVar) String() string {
+	return me.Level().String()
+}
+
+func (me *LevelVar) MarshalText() ([]byte, error) {
+	return me.Level().MarshalText()
+}
+
+func (me *LevelVar) UnmarshalText(text []byte) error {
+	var level Level
+	err := level.UnmarshalText(text)
+	if err != nil {
+		return err
+	}
+	me.Set(level)
+	return nil
+}
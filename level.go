@@ -2,7 +2,10 @@ package log
 
 import (
 	"encoding"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"log/slog"
 	"strconv"
 	"strings"
 )
@@ -11,17 +14,21 @@ type Level struct {
 	rank int
 }
 
+// The distance between the ranks of the predefined levels, leaving room to register levels
+// between them.
+const levelRankSpacing = 1 << 16
+
 var (
 	Never    = Level{-1} // A message at this level should never be logged.
 	NotSet   = Level{0}
-	Debug    = Level{1}
-	Info     = Level{2}
-	Warning  = Level{3}
-	Error    = Level{4}
-	Critical = Level{5}
+	Debug    = Level{1 * levelRankSpacing}
+	Info     = Level{2 * levelRankSpacing}
+	Warning  = Level{3 * levelRankSpacing}
+	Error    = Level{4 * levelRankSpacing}
+	Critical = Level{5 * levelRankSpacing}
 	// It shouldn't be possible to define a message at this level. Filtering at this level should
 	// mean no messages ever get through.
-	Disabled = Level{6}
+	Disabled = Level{6 * levelRankSpacing}
 )
 
 func (l Level) isNotSet() bool {
@@ -29,22 +36,10 @@ func (l Level) isNotSet() bool {
 }
 
 func (l Level) LogString() string {
-	switch l.rank {
-	case NotSet.rank:
-		return "NIL"
-	case Debug.rank:
-		return "DBG"
-	case Info.rank:
-		return "INF"
-	case Warning.rank:
-		return "WRN"
-	case Error.rank:
-		return "ERR"
-	case Critical.rank:
-		return "CRT"
-	default:
-		return strconv.FormatInt(int64(l.rank), 10)
+	if def, ok := lookupLevel(l); ok {
+		return def.ShortName
 	}
+	return strconv.FormatInt(int64(l.rank), 10)
 }
 
 // Not sure why we didn't define this. Show LogString as the default for human-readable
@@ -60,28 +55,66 @@ func (l Level) LessThan(r Level) bool {
 	return l.rank < r.rank
 }
 
-var _ encoding.TextUnmarshaler = (*Level)(nil)
+var (
+	_ encoding.TextMarshaler   = Level{}
+	_ encoding.TextUnmarshaler = (*Level)(nil)
+	_ json.Marshaler           = Level{}
+	_ json.Unmarshaler         = (*Level)(nil)
+	_ flag.Value               = (*Level)(nil)
+	_ slog.Leveler             = Level{}
+)
+
+// Returns the long name of the level, such as "warning". Levels that aren't registered can't be
+// marshalled.
+func (l Level) MarshalText() ([]byte, error) {
+	def, ok := lookupLevel(l)
+	if !ok {
+		return nil, fmt.Errorf("unregistered log level: %v", l.rank)
+	}
+	name := def.LongName
+	if name == "" {
+		name = strings.ToLower(def.ShortName)
+	}
+	return []byte(name), nil
+}
 
 func (l *Level) UnmarshalText(text []byte) error {
-	switch strings.ToLower(string(text)) {
-	case "nil", "notset", "unset", "all", "*":
-		*l = NotSet
-	case "dbg", "debug":
-		*l = Debug
-	case "inf", "info":
-		*l = Info
-	case "wrn", "warning", "warn":
-		*l = Warning
-	case "err", "error":
-		*l = Error
-	case "crt", "critical", "crit":
-		*l = Critical
-	default:
+	level, ok := loadLevels().byName[strings.ToLower(string(text))]
+	if !ok {
 		return fmt.Errorf("unknown log level: %q", text)
 	}
+	*l = level
 	return nil
 }
 
+func (l Level) MarshalJSON() ([]byte, error) {
+	text, err := l.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+func (l *Level) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	return l.UnmarshalText([]byte(s))
+}
+
+// Implements flag.Value.
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
+
+// Implements slog.Leveler, treating l as a minimum level, such as for slog.HandlerOptions. NotSet
+// allows all levels, and Disabled none.
+func (l Level) Level() slog.Level {
+	return toSlogMinLevel(l)
+}
+
 func (l Level) Or(r Level) Level {
 	if l.isNotSet() {
 		return r
@@ -0,0 +1,160 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRegisterLevel(t *testing.T) {
+	c := qt.New(t)
+	defer registeredLevels.Store(registeredLevels.Load())
+	defer ReplaceRules(ReplaceRules(nil))
+	trace := RegisterLevel(LevelDef{Above: NotSet, ShortName: "TRC", LongName: "trace", SlogLevel: slog.LevelDebug - 4})
+	notice := RegisterLevel(LevelDef{Above: Info, ShortName: "NTC", LongName: "notice", SlogLevel: slog.LevelInfo + 2})
+	fatal := RegisterLevel(LevelDef{Above: Critical, ShortName: "FTL", LongName: "fatal", Aliases: []string{"die"}, SlogLevel: slog.LevelError + 4})
+	// Ordering.
+	for _, pair := range [][2]Level{{trace, Debug}, {Info, notice}, {notice, Warning}, {Critical, fatal}, {fatal, Disabled}} {
+		c.Check(pair[0].LessThan(pair[1]), qt.IsTrue, qt.Commentf("%v < %v", pair[0], pair[1]))
+		c.Check(pair[1].LessThan(pair[0]), qt.IsFalse)
+	}
+	c.Check(levelBelow(Debug), qt.Equals, trace)
+	c.Check(levelBelow(Warning), qt.Equals, notice)
+	// Names.
+	c.Check(notice.LogString(), qt.Equals, "NTC")
+	for text, expected := range map[string]Level{"trace": trace, "TRC": trace, "Notice": notice, "die": fatal} {
+		var level Level
+		c.Check(level.UnmarshalText([]byte(text)), qt.IsNil)
+		c.Check(level, qt.Equals, expected, qt.Commentf("%q", text))
+	}
+	// Slog mapping.
+	sl, ok := toSlogLevel(notice)
+	c.Check(ok, qt.IsTrue)
+	c.Check(sl, qt.Equals, slog.LevelInfo+2)
+	c.Check(fromSlogLevel(slog.LevelError+4), qt.Equals, fatal)
+	// Rules and logging.
+	c.Assert(SetRules("level-test=notice"), qt.IsNil)
+	c.Check(describeRule(MinLevel(NameContains("level-test"), notice)), qt.Equals, "level-test=ntc")
+	rs := make(chan Record, 1)
+	l := NewLogger("level-test")
+	l.SetHandlers(chanHandler{rs})
+	l.Levelf(Info, "filtered")
+	l.Slogger().Log(context.Background(), slog.LevelInfo+2, "logged")
+	r := <-rs
+	c.Check(r.Level, qt.Equals, notice)
+	c.Check(r.Msg.String(), qt.Equals, "logged")
+	// Names must be unique.
+	c.Check(func() { RegisterLevel(LevelDef{Above: Debug, ShortName: "ntc"}) }, qt.PanicMatches, `level name "ntc" is already registered`)
+	c.Check(func() { RegisterLevel(LevelDef{Above: Disabled, ShortName: "MAX"}) }, qt.PanicMatches, `can't register a level above .*`)
+	c.Check(func() { RegisterLevel(LevelDef{Above: Level{5}, ShortName: "ODD"}) }, qt.PanicMatches, `level .* is not registered`)
+	// The slog level must keep the order of the neighbouring levels. The zero value is slog's Info.
+	for _, def := range []LevelDef{
+		{Above: NotSet, ShortName: "LOW"},
+		{Above: NotSet, ShortName: "LOW", SlogLevel: slog.LevelDebug - 4},
+		{Above: Debug, ShortName: "MID", SlogLevel: slog.LevelDebug},
+		{Above: Info, ShortName: "MID", SlogLevel: slog.LevelWarn},
+		{Above: fatal, ShortName: "TOP", SlogLevel: slog.LevelError + 4},
+	} {
+		c.Check(func() { RegisterLevel(def) }, qt.PanicMatches, `slog level .* must be between .*`, qt.Commentf("%+v", def))
+	}
+	c.Check(fromSlogLevel(slog.LevelInfo), qt.Equals, Info)
+}
+
+func TestSlogLevelMapping(t *testing.T) {
+	c := qt.New(t)
+	for sl, expected := range map[slog.Level]Level{
+		slog.LevelDebug - 4: Debug,
+		slog.LevelDebug:     Debug,
+		slog.LevelInfo + 2:  Info,
+		slog.LevelWarn:      Warning,
+		slog.LevelError + 1: Critical,
+		slog.LevelError + 8: Critical,
+	} {
+		level, exact := fromSlogLevelExact(sl)
+		c.Check(level, qt.Equals, expected, qt.Commentf("%v", sl))
+		c.Check(exact, qt.Equals, sl == slog.LevelDebug || sl == slog.LevelWarn || sl == slog.LevelError+1)
+	}
+	sl, exact := toSlogLevel(Level{Info.rank + 1})
+	c.Check(sl, qt.Equals, slog.LevelInfo)
+	c.Check(exact, qt.IsFalse)
+	sl, exact = toSlogLevel(Level{1})
+	c.Check(sl, qt.Equals, slog.LevelDebug-1)
+	c.Check(exact, qt.IsFalse)
+	c.Check(toSlogMinLevel(Never), qt.Equals, slog.Level(math.MinInt))
+}
+
+func TestSlogLevelRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	var buf bytes.Buffer
+	rs := make(chan Record, 1)
+	l := NewLogger("slog-round-trip").WithFilterLevel(NotSet)
+	l.SetHandlers(
+		SlogHandlerAsHandler{slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug - 8})},
+		chanHandler{rs},
+	)
+	l.Slogger().Log(context.Background(), slog.LevelInfo+2, "hello")
+	r := <-rs
+	c.Check(r.Level, qt.Equals, Info)
+	sl, ok := recordSlogLevel(r)
+	c.Check(ok, qt.IsTrue)
+	c.Check(sl, qt.Equals, slog.LevelInfo+2)
+	var out map[string]any
+	c.Assert(json.Unmarshal(buf.Bytes(), &out), qt.IsNil)
+	c.Check(out["level"], qt.Equals, "INFO+2")
+	c.Check(out, qt.Not(qt.Contains), SlogLevelKey)
+	// Exact levels don't get the value.
+	buf.Reset()
+	l.Slogger().Warn("hello")
+	r = <-rs
+	c.Check(r.Level, qt.Equals, Warning)
+	_, ok = recordSlogLevel(r)
+	c.Check(ok, qt.IsFalse)
+}
+
+func TestLevelMarshalling(t *testing.T) {
+	c := qt.New(t)
+	for _, level := range []Level{Never, NotSet, Debug, Info, Warning, Error, Critical, Disabled} {
+		c.Run(level.String(), func(c *qt.C) {
+			text, err := level.MarshalText()
+			c.Assert(err, qt.IsNil)
+			var fromText Level
+			c.Assert(fromText.UnmarshalText(text), qt.IsNil)
+			c.Check(fromText, qt.Equals, level)
+
+			type config struct {
+				Level Level
+			}
+			b, err := json.Marshal(config{level})
+			c.Assert(err, qt.IsNil)
+			c.Check(string(b), qt.Equals, `{"Level":"`+string(text)+`"}`)
+			var fromJson config
+			c.Assert(json.Unmarshal(b, &fromJson), qt.IsNil)
+			c.Check(fromJson.Level, qt.Equals, level)
+
+			var fromFlag Level
+			fs := flag.NewFlagSet("", flag.ContinueOnError)
+			fs.Var(&fromFlag, "level", "")
+			c.Assert(fs.Parse([]string{"-level", level.String()}), qt.IsNil)
+			c.Check(fromFlag, qt.Equals, level)
+		})
+	}
+	var level Level
+	c.Check(level.UnmarshalText([]byte("loud")), qt.ErrorMatches, `unknown log level: "loud"`)
+	c.Check(json.Unmarshal([]byte(`3`), &level), qt.IsNotNil)
+	_, err := Level{1}.MarshalText()
+	c.Check(err, qt.IsNotNil)
+	// As a slog.Leveler, levels are minimums.
+	c.Check(Warning.Level(), qt.Equals, slog.LevelWarn)
+	c.Check(NotSet.Level(), qt.Equals, slog.Level(math.MinInt))
+	c.Check(Disabled.Level(), qt.Equals, slog.Level(math.MaxInt))
+	h := slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: Info})
+	c.Check(h.Enabled(context.Background(), slog.LevelDebug), qt.IsFalse)
+	c.Check(h.Enabled(context.Background(), slog.LevelInfo), qt.IsTrue)
+}
@@ -1,7 +1,11 @@
 package log
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"strconv"
 	"testing"
 
@@ -40,6 +44,20 @@ func (c chanHandler) Handle(r Record) {
 	c.r <- r
 }
 
+// Returns a handler for a single record at a time, and a function that takes the handled record,
+// reporting whether there was one. This is for checking whether each message is logged.
+func newRecordTaker() (chanHandler, func() (Record, bool)) {
+	rs := make(chan Record, 1)
+	return chanHandler{rs}, func() (r Record, ok bool) {
+		select {
+		case r = <-rs:
+			return r, true
+		default:
+			return
+		}
+	}
+}
+
 func TestErrorLevelHandling(t *testing.T) {
 	c := qt.New(t)
 	l := NewLogger("test").FilterLevel(NotSet)
@@ -62,3 +80,153 @@ func TestErrorLevelHandling(t *testing.T) {
 	testLogging(l.defaultLevel, func() { l.Levelf(ErrorLevel(err), "error without level: %v", err) })
 	testLogging(Warning, func() { l.Levelf(ErrorLevel(WithLevel(Warning, err)), "error with level: %v", err) })
 }
+
+func TestEnabled(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	l := NewLogger("enabled-test").WithFilterLevel(Warning)
+	sl := l.Slogger()
+	c.Check(l.EnabledAt(Debug, 0), qt.IsFalse)
+	c.Check(l.EnabledAt(Warning, 0), qt.IsTrue)
+	c.Check(sl.Enabled(context.Background(), slog.LevelDebug), qt.IsFalse)
+	c.Assert(SetRules("enabled-test+log_test.go=debug"), qt.IsNil)
+	c.Check(l.EnabledAt(Debug, 0), qt.IsTrue)
+	c.Check(sl.Enabled(context.Background(), slog.LevelDebug), qt.IsTrue)
+	// The rule applies to the caller location, which for a skip of 1 is in the testing package.
+	c.Check(l.EnabledAt(Debug, 1), qt.IsFalse)
+}
+
+func TestContextWithForcedLevel(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	c.Assert(SetRules("forced-level-test=error"), qt.IsNil)
+	h, takeRecord := newRecordTaker()
+	l := NewLogger("forced-level-test").WithFilterLevel(Warning)
+	l.SetHandlers(h)
+	logged := func(ctx context.Context, level Level) bool {
+		ContextLogger(ctx).Levelf(level, "hello")
+		_, ok := takeRecord()
+		return ok
+	}
+	ctx := ContextWithLogger(context.Background(), l)
+	c.Check(logged(ctx, Warning), qt.IsFalse)
+	forcedCtx := ContextWithForcedLevel(ctx, Debug)
+	c.Check(logged(forcedCtx, Debug), qt.IsTrue)
+	c.Check(ContextLogger(forcedCtx).EnabledAt(Debug, 0), qt.IsTrue)
+	c.Check(logged(ContextWithForcedLevel(ctx, Info), Debug), qt.IsFalse)
+	c.Check(logged(ctx, Debug), qt.IsFalse)
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	defer func(level Level) { Default.defaultLevel = level }(Default.defaultLevel)
+	env := map[string]string{
+		EnvRules:        "dht=debug,torrent=bogus",
+		EnvDefaultLevel: "loud",
+	}
+	getenv := func(key string) string { return env[key] }
+	errs := configureFromEnv(getenv)
+	c.Assert(errs, qt.HasLen, 2)
+	c.Check(errs[0], qt.ErrorMatches, `\$GO_LOG: parsing rule "torrent=bogus" at offset 10: .*`)
+	var syntaxErr *RuleSyntaxError
+	c.Assert(errors.As(errs[0], &syntaxErr), qt.IsTrue)
+	c.Check(syntaxErr.Offset, qt.Equals, 10)
+	c.Check(errs[1], qt.ErrorMatches, `\$GO_LOG_DEFAULT_LEVEL: unknown log level: "loud"`)
+	// Invalid configuration falls back to the defaults.
+	c.Check(Rules(), qt.HasLen, 0)
+	c.Check(Default.defaultLevel, qt.Equals, NotSet)
+	env = map[string]string{
+		EnvRules:        "dht=debug",
+		EnvDefaultLevel: "info",
+	}
+	c.Check(configureFromEnv(getenv), qt.HasLen, 0)
+	c.Check(Rules(), qt.HasLen, 1)
+	c.Check(Default.defaultLevel, qt.Equals, Info)
+}
+
+func TestVerbositySignals(t *testing.T) {
+	c := qt.New(t)
+	defer defaultFilterLevel.Set(defaultFilterLevel.Level())
+	defer ReplaceRules(ReplaceRules(nil))
+	defer Default.SetHandlers(Default.Handlers...)
+	h, takeRecord := newRecordTaker()
+	Default.SetHandlers(h)
+	// Rules don't hide the changes.
+	c.Assert(SetRules("*="), qt.IsNil)
+	defaultFilterLevel.Set(Warning)
+	for _, expected := range []Level{Info, Debug, NotSet} {
+		lowerDefaultFilterLevel()
+		c.Check(Default.currentFilterLevel(), qt.Equals, expected)
+		r, ok := takeRecord()
+		c.Assert(ok, qt.IsTrue)
+		c.Check(r.Text(), qt.Matches, `changed default filter level from .* to `+expected.LogString())
+	}
+	lowerDefaultFilterLevel()
+	c.Check(Default.currentFilterLevel(), qt.Equals, NotSet)
+	restoreDefaultFilterLevel()
+	c.Check(Default.currentFilterLevel(), qt.Equals, Warning)
+	takeRecord()
+	// Restoring again does nothing.
+	defaultFilterLevel.Set(Error)
+	restoreDefaultFilterLevel()
+	c.Check(Default.currentFilterLevel(), qt.Equals, Error)
+}
+
+func TestWithFilterLeveler(t *testing.T) {
+	c := qt.New(t)
+	var v LevelVar
+	v.Set(Warning)
+	h, takeRecord := newRecordTaker()
+	parent := NewLogger("leveler-test").WithFilterLeveler(&v)
+	parent.SetHandlers(h)
+	child := parent.WithNames("child")
+	logged := func(l Logger) bool {
+		l.Levelf(Info, "hello")
+		_, ok := takeRecord()
+		return ok
+	}
+	c.Check(logged(child), qt.IsFalse)
+	v.Set(Info)
+	c.Check(logged(parent), qt.IsTrue)
+	c.Check(logged(child), qt.IsTrue)
+	c.Check(logged(child.WithFilterLevel(Error)), qt.IsFalse)
+	c.Check(v.UnmarshalText([]byte("error")), qt.IsNil)
+	c.Check(v.Level(), qt.Equals, Error)
+	c.Check(logged(child), qt.IsFalse)
+}
+
+func TestStackTrace(t *testing.T) {
+	c := qt.New(t)
+	rs := make(chan Record, 1)
+	var buf bytes.Buffer
+	l := NewLogger("stack-test").WithFilterLevel(NotSet).WithStackTraceLevel(Error)
+	l.SetHandlers(chanHandler{rs}, SlogHandlerAsHandler{slog.NewJSONHandler(&buf, nil)})
+	l.Levelf(Warning, "no stack")
+	c.Check((<-rs).Stack, qt.HasLen, 0)
+	buf.Reset()
+	l.Levelf(Error, "stack")
+	r := <-rs
+	c.Assert(r.Stack, qt.Not(qt.HasLen), 0)
+	c.Check(len(r.Stack) <= maxStackTraceDepth, qt.IsTrue)
+	frame, _ := r.Stack.Frames().Next()
+	c.Check(frame.Function, qt.Equals, "github.com/anacrolix/log.TestStackTrace")
+	c.Check(string(twoLineFormatter(r)), qt.Matches, `(?s)\[.*\]\n  .*\n  stack:\n    github.com/anacrolix/log.TestStackTrace .*/log_test.go:\d+\n.*`)
+	c.Check(string(LineFormatter(r)), qt.Matches, `(?s)\[.*\] .*\[.*\]\n  stack:\n    github.com/anacrolix/log.TestStackTrace .*`)
+	var out map[string]any
+	c.Assert(json.Unmarshal(buf.Bytes(), &out), qt.IsNil)
+	c.Assert(out[StackTraceKey], qt.Not(qt.HasLen), 0)
+	c.Check(out[StackTraceKey].([]any)[0], qt.Matches, `github.com/anacrolix/log.TestStackTrace .*`)
+	// Records from log/slog get the whole stack from the logging call, not just its frame.
+	buf.Reset()
+	l.Slogger().Error("stack")
+	r = <-rs
+	frames := r.Stack.Frames()
+	frame, _ = frames.Next()
+	c.Check(frame.Function, qt.Equals, "github.com/anacrolix/log.TestStackTrace")
+	frame, _ = frames.Next()
+	c.Check(frame.Function, qt.Equals, "testing.tRunner")
+	// NotSet disables stack traces.
+	l.WithStackTraceLevel(NotSet).Levelf(Critical, "no stack")
+	c.Check((<-rs).Stack, qt.HasLen, 0)
+}
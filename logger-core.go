@@ -2,7 +2,6 @@ package log
 
 import (
 	"fmt"
-	"path/filepath"
 	"slices"
 )
 
@@ -15,8 +14,18 @@ type loggerCore struct {
 	defaultLevel Level
 	// Use propagation on NOTSET.
 	filterLevel Level
-	msgMaps     []func(Msg) Msg
-	Handlers    []Handler
+	// If set, the filter level is loaded from here instead, so it can be changed while in use.
+	filterLevelVar *LevelVar
+	// If set, messages at this level or higher are logged before considering rules or filterLevel.
+	forcedLevel Level
+	// Messages at this level or higher get a stack trace. NotSet means none do.
+	stackTraceLevel Level
+	// The fraction of messages to keep after filtering. Zero means no sampling.
+	sampleRate float64
+	msgMaps    []func(Msg) Msg
+	Handlers   []Handler
+	// Shared by copies of the loggerCore that have the same names.
+	callSites *callSites
 }
 
 func (l loggerCore) asLogger() Logger {
@@ -43,6 +52,38 @@ func (l loggerCore) WithDefaultLevel(level Level) Logger {
 
 func (l loggerCore) WithFilterLevel(minLevel Level) Logger {
 	l.filterLevel = minLevel
+	l.filterLevelVar = nil
+	return l.asLogger()
+}
+
+// Returns a Logger with its filter level loaded from v each time it's used, so the filter level of
+// the Logger and any derived from it can be changed at runtime with v.Set. Derived Loggers stop
+// following v if they're given a filter level of their own.
+func (l loggerCore) WithFilterLeveler(v *LevelVar) Logger {
+	l.filterLevelVar = v
+	return l.asLogger()
+}
+
+// Returns the filter level currently in effect.
+func (l loggerCore) currentFilterLevel() Level {
+	if l.filterLevelVar != nil {
+		return l.filterLevelVar.Level()
+	}
+	return l.filterLevel
+}
+
+// Returns a Logger that keeps only the given fraction of messages that pass filtering. The fraction
+// kept is added to messages as a value with the key SampleRateKey. This combines with sampling from
+// rules.
+func (l loggerCore) WithSampling(rate float64) Logger {
+	l.sampleRate = rate
+	return l.asLogger()
+}
+
+// Returns a Logger that captures a stack trace for messages at the given level or higher, and
+// adds it to the Record given to handlers. NotSet disables stack traces.
+func (l loggerCore) WithStackTraceLevel(level Level) Logger {
+	l.stackTraceLevel = level
 	return l.asLogger()
 }
 
@@ -56,12 +97,52 @@ func (l loggerCore) IsZero() bool {
 	return !l.nonZero
 }
 
-// Deprecated. This should require a msg, since filtering includes the location a msg is created at.
-// That would require building a message before we can do checks, which means lazily constructing
-// the message but not the caller location.
+// Deprecated: Use EnabledAt, which makes it explicit which caller is used to apply rules.
 func (l loggerCore) IsEnabledFor(level Level) bool {
-	// TODO: Take a message?
-	return true
+	return l.EnabledAt(level, 1)
+}
+
+// Reports whether a message at the given level would be logged if it were logged from the caller
+// skip frames above the caller of EnabledAt. This allows skipping expensive construction of
+// messages that would be filtered. If the rules in effect depend on message values, this assumes
+// the message would be logged.
+func (l loggerCore) EnabledAt(level Level, skip int) bool {
+	return l.enabledAtPc(level, getSingleCallerPc(skip+1))
+}
+
+func (l loggerCore) enabledAtPc(level Level, pc uintptr) bool {
+	if !loadRuleSet().cacheable {
+		return true
+	}
+	_, match := l.callSiteAndRules(pc, Msg{})
+	level = l.resolveLevel(level, match)
+	return l.passesForcedLevel(level) || l.passesFilter(level, match)
+}
+
+// Whether a message at level is logged due to a forced level, such as from ContextWithForcedLevel.
+func (l loggerCore) passesForcedLevel(level Level) bool {
+	return !l.forcedLevel.isNotSet() && !level.LessThan(l.forcedLevel)
+}
+
+// Returns the level a message will have if it's logged at the given level, given the outcome of
+// applying rules to it. Default level rules take precedence over the Logger's default level.
+func (l loggerCore) resolveLevel(level Level, match ruleMatch) Level {
+	if !level.isNotSet() {
+		return level
+	}
+	if !match.defaultLevel.isNotSet() {
+		return match.defaultLevel
+	}
+	return l.defaultLevel
+}
+
+// Determines whether a message with the given level passes filtering, given the outcome of
+// applying rules to it.
+func (l loggerCore) passesFilter(level Level, match ruleMatch) bool {
+	if match.matched() {
+		return !level.LessThan(match.level)
+	}
+	return !level.LessThan(l.currentFilterLevel())
 }
 
 func (l loggerCore) LazyLog(level Level, f func() Msg) {
@@ -73,37 +154,48 @@ func (l loggerCore) LazyLogDefaultLevel(f func() Msg) {
 }
 
 func (l loggerCore) lazyLog(level Level, skip int, f func() Msg) {
-	if level.isNotSet() {
-		level = l.defaultLevel
-	}
 	r := f().Skip(skip + 1)
-	msgLoc := getMsgLogLoc(r)
-	names := append(
-		l.names[:len(l.names):len(l.names)],
-		msgLoc.Package,
-		fmt.Sprintf("%v:%v", filepath.Base(msgLoc.File), msgLoc.Line),
-	)
-	if rulesLevel, ok := levelFromRules(names); ok {
-		if level.LessThan(rulesLevel) {
+	pc := getMsgPc(r)
+	cs, match := l.callSiteAndRules(pc, r)
+	level = l.resolveLevel(level, match)
+	hits := cs.seen.hit()
+	sampleRate := 1.0
+	if !l.passesForcedLevel(level) {
+		if !l.passesFilter(level, match) {
+			return
+		}
+		sampleRate = l.combinedSampleRate(match)
+		if sampleRate < 1 && !keepSample(sampleRate, cs.seen.key, hits) {
+			return
+		}
+		if rl, ok := ruleAs[*rateLimitedRule](match.rule); ok && !rl.allow(l, level, cs) {
 			return
 		}
-	} else if level.LessThan(l.filterLevel) {
-		return
 	}
 	for i := len(l.msgMaps) - 1; i >= 0; i-- {
 		r = l.msgMaps[i](r)
 	}
+	if sampleRate < 1 {
+		r = r.With(SampleRateKey, sampleRate)
+	}
 	r = r.WithValues(l.values...)
-	l.handle(level, r, names)
+	var stack StackTrace
+	if l.capturesStackTraceAt(level) {
+		stack = captureStackTrace(skip+1, pc)
+	}
+	cs.seen.handled()
+	l.handle(level, r, cs, stack)
 }
 
 // Goes from an affirmative decision to log, to sending it to the handlers in the right form.
-func (l loggerCore) handle(level Level, m Msg, names []string) {
+func (l loggerCore) handle(level Level, m Msg, cs *callSite, stack StackTrace) {
 	r := Record{
 		// Do we really need to be passing the full Msg caller context at this point?
-		Msg:   m.Skip(1),
-		Level: level,
-		Names: names,
+		Msg:    m.Skip(1),
+		Level:  level,
+		Names:  cs.names,
+		Origin: cs.origin,
+		Stack:  stack,
 	}
 	// I'm not sure we care if something is initialized anymore...
 	//l.assertNonZero()
@@ -116,6 +208,7 @@ func (l loggerCore) WithNames(names ...string) Logger {
 	// Avoid sharing after appending. This might not be enough because some formatters might add
 	// more elements concurrently, or names could be empty.
 	l.names = append(l.names[:len(l.names):len(l.names)], names...)
+	l.callSites = new(callSites)
 	return l.asLogger()
 }
 
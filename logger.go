@@ -32,13 +32,13 @@ func (l Logger) WithText(f func(Msg) string) Logger {
 
 // Helper for compatibility with "log".Logger.
 func (l Logger) Printf(format string, a ...interface{}) {
-	l.LazyLog(l.defaultLevel, func() Msg {
+	l.LazyLog(NotSet, func() Msg {
 		return Fmsg(format, a...).Skip(1)
 	})
 }
 
 func (l Logger) Log(m Msg) {
-	l.LogLevel(l.defaultLevel, m.Skip(1))
+	l.LogLevel(NotSet, m.Skip(1))
 }
 
 func (l Logger) LogLevel(level Level, m Msg) {
@@ -49,7 +49,7 @@ func (l Logger) LogLevel(level Level, m Msg) {
 
 // Helper for compatibility with "log".Logger.
 func (l Logger) Print(v ...interface{}) {
-	l.LazyLog(l.defaultLevel, func() Msg {
+	l.LazyLog(NotSet, func() Msg {
 		return Str(fmt.Sprint(v...)).Skip(1)
 	})
 }
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The structured form of the names of a message: where it was logged from, and the names of the
+// Logger it was logged through.
+type Origin struct {
+	// The names added to the Logger, such as with WithNames.
+	LoggerNames []string
+	// The full import path of the package.
+	Package string
+	// The function name without the package path. See Loc.ShortFunction.
+	Function string
+	// The short source file name.
+	File string
+	Line int
+}
+
+func originFromLoc(loggerNames []string, loc Loc) Origin {
+	return Origin{
+		LoggerNames: loggerNames,
+		Package:     loc.Package,
+		Function:    loc.ShortFunction(),
+		File:        filepath.Base(loc.File),
+		Line:        loc.Line,
+	}
+}
+
+// Recovers the Origin from a flat view of names, as returned by Origin.Names. If there are too
+// few names, they're all treated as Logger names.
+func originFromNames(names []string) (o Origin) {
+	if len(names) < 3 {
+		o.LoggerNames = names
+		return
+	}
+	n := len(names) - 3
+	o.LoggerNames = names[:n:n]
+	o.Package = names[n]
+	o.Function = names[n+1]
+	file, line, _ := strings.Cut(names[n+2], ":")
+	o.File = file
+	o.Line, _ = strconv.Atoi(line)
+	return
+}
+
+// Returns the short file name and line number joined like "file.go:42".
+func (o Origin) FileLine() string {
+	return o.File + ":" + strconv.Itoa(o.Line)
+}
+
+// Returns the flat view of the names, as used in Record.Names. This is the Logger names followed
+// by the package, function and FileLine.
+func (o Origin) Names() []string {
+	return append(
+		o.LoggerNames[:len(o.LoggerNames):len(o.LoggerNames)],
+		o.Package,
+		o.Function,
+		o.FileLine(),
+	)
+}
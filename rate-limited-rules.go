@@ -0,0 +1,112 @@
+package log
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A rule that limits how many messages it allows for each permutation of names. Messages over the
+// limit are dropped, and counted in a summary that is logged once the period has passed.
+type rateLimitedRule struct {
+	InputRule
+	// Messages allowed per period. It's also the burst size.
+	limit  float64
+	period time.Duration
+	// *namesSeen -> *tokenBucket
+	buckets sync.Map
+}
+
+type tokenBucket struct {
+	mu               sync.Mutex
+	tokens           float64
+	last             time.Time
+	dropped          uint64
+	summaryScheduled bool
+}
+
+// Parses a rate option like "50/s" or "10/5m", and applies it to rule.
+func parseRateLimit(rule InputRule, opt string) (InputRule, error) {
+	countStr, periodStr, _ := strings.Cut(opt, "/")
+	count, err := strconv.ParseUint(countStr, 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parsing count: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("count must be positive")
+	}
+	period, err := time.ParseDuration(periodStr)
+	if err != nil {
+		// Allow "s" for "1s".
+		period, err = time.ParseDuration("1" + periodStr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing period: %w", err)
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf("period must be positive")
+	}
+	return &rateLimitedRule{
+		InputRule: rule,
+		limit:     float64(count),
+		period:    period,
+	}, nil
+}
+
+func (me *rateLimitedRule) unwrapRule() InputRule {
+	return me.InputRule
+}
+
+func (me *rateLimitedRule) namesOnly() bool {
+	return ruleIsNamesOnly(me.InputRule)
+}
+
+func (me *rateLimitedRule) String() string {
+	return describeRule(me.InputRule)
+}
+
+func (me *rateLimitedRule) bucket(seen *namesSeen) *tokenBucket {
+	v, ok := me.buckets.Load(seen)
+	if !ok {
+		v, _ = me.buckets.LoadOrStore(seen, &tokenBucket{
+			tokens: me.limit,
+			last:   timeNow(),
+		})
+	}
+	return v.(*tokenBucket)
+}
+
+// Takes a token for a message about to be logged by l from cs. If none are available, the message
+// is counted for the summary and false is returned.
+func (me *rateLimitedRule) allow(l loggerCore, level Level, cs *callSite) bool {
+	b := me.bucket(cs.seen)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := timeNow()
+	b.tokens = math.Min(me.limit, b.tokens+now.Sub(b.last).Seconds()*me.limit/me.period.Seconds())
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	b.dropped++
+	if !b.summaryScheduled {
+		b.summaryScheduled = true
+		time.AfterFunc(me.period, func() {
+			me.summarize(l, level, cs, b)
+		})
+	}
+	return false
+}
+
+func (me *rateLimitedRule) summarize(l loggerCore, level Level, cs *callSite, b *tokenBucket) {
+	b.mu.Lock()
+	dropped := b.dropped
+	b.dropped = 0
+	b.summaryScheduled = false
+	b.mu.Unlock()
+	l.handle(level, Fmsg("suppressed %v messages from %v", dropped, strings.Join(cs.names, " ")), cs, nil)
+}
@@ -1,8 +1,6 @@
 package log
 
 import (
-	"io"
-	"log"
 	"os"
 	"sync"
 
@@ -10,7 +8,8 @@ import (
 )
 
 type nameToAny struct {
-	emptyCase bool
+	// Set if this permutation of names has been seen.
+	emptyCase *namesSeen
 	children  map[string]*nameToAny
 }
 
@@ -21,13 +20,10 @@ type reportedNamesType struct {
 	base nameToAny
 }
 
-func putReportInner(toAny *nameToAny, names []string) bool {
+// Returns where the entry for names is stored, creating intermediate nodes as required.
+func putReportInner(toAny *nameToAny, names []string) **namesSeen {
 	if len(names) == 0 {
-		if toAny.emptyCase {
-			return false
-		}
-		toAny.emptyCase = true
-		return true
+		return &toAny.emptyCase
 	}
 	g.MakeMapIfNil(&toAny.children)
 	child, ok := toAny.children[names[0]]
@@ -38,28 +34,48 @@ func putReportInner(toAny *nameToAny, names []string) bool {
 	return putReportInner(child, names[1:])
 }
 
-// Prevent duplicate logs about the same series of names.
-func (me *reportedNamesType) putReport(names []string) bool {
+// Returns the entry for a permutation of names, and whether it was added. Reporting on only added
+// names prevents duplicate logs about the same series of names. origin is the structured form of
+// names, and filterLevel and filterLevelVar are the filter level of the Logger that added it.
+func (me *reportedNamesType) put(names []string, origin Origin, filterLevel Level, filterLevelVar *LevelVar) (_ *namesSeen, added bool) {
 	me.mu.Lock()
 	defer me.mu.Unlock()
-	return putReportInner(&me.base, names)
+	seen := putReportInner(&me.base, names)
+	if *seen == nil {
+		*seen = &namesSeen{
+			names:          names,
+			origin:         origin,
+			filterLevel:    filterLevel,
+			filterLevelVar: filterLevelVar,
+			key:            hashNames(names),
+		}
+		added = true
+	}
+	return *seen, added
 }
 
-var reportRulesLogger = log.New(os.Stderr, "anacrolix/log: ", 0)
+// Receives an explanation of the level required for each previously unseen permutation of names.
+// It's set to a Handler writing to stderr if EnvReportRules is set, and is nil otherwise, which
+// disables reporting. It should be changed before logging begins.
+var ReportRulesHandler Handler
 
 func init() {
-	if os.Getenv(EnvReportRules) == "" {
-		reportRulesLogger.SetOutput(io.Discard)
+	if os.Getenv(EnvReportRules) != "" {
+		ReportRulesHandler = StreamHandler{
+			W:   os.Stderr,
+			Fmt: twoLineFormatter,
+		}
 	}
 }
 
-func reportLevelFromRules(level Level, ok bool, names []string) {
-	if !reportedNames.putReport(names) {
-		return
-	}
-	if !ok {
-		reportRulesLogger.Printf("no rule matched for %q", names)
+func reportExplanation(e Explanation) {
+	h := ReportRulesHandler
+	if h == nil {
 		return
 	}
-	reportRulesLogger.Printf("got level %v for %q", level.LogString(), names)
+	h.Handle(Record{
+		Msg:   Str(e.String()),
+		Level: e.Level,
+		Names: e.Names,
+	})
 }
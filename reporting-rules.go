@@ -1,51 +1,416 @@
 package log
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 )
 
-var rules []Rule
-
+// Decides the minimum level a message with the given names must have to be logged, and whether
+// the rule applies to it at all.
 type Rule func(names []string) (level Level, matched bool)
 
-func alwaysLevel(level Level) Rule {
-	return func(names []string) (Level, bool) {
-		return level, true
+// A rule that decides the minimum level a message must have to be logged from all the details of
+// the message, including its values and origin. Rule implements it, as do the rules parsed from
+// GO_LOG.
+type InputRule interface {
+	// Returns the minimum level for the message described by input, and whether the rule applies
+	// to it at all.
+	Apply(input RuleInput) (level Level, matched bool)
+}
+
+// The details of a message that are made available to an InputRule.
+type RuleInput struct {
+	// The flat view of Origin.
+	Names  []string
+	Origin Origin
+	// The message with the values of the Logger added.
+	msg Msg
+}
+
+// Iterates over the values of the message, including those added by the Logger.
+func (in RuleInput) Values(callback func(value interface{}) (more bool)) {
+	if in.msg.MsgImpl == nil {
+		return
+	}
+	in.msg.Values(callback)
+}
+
+// Returns the first value added to the message with the given key, such as with [Msg.With].
+func (in RuleInput) Lookup(key interface{}) (value interface{}, ok bool) {
+	in.Values(func(v interface{}) bool {
+		if item, isItem := v.(item); isItem && item.key == key {
+			value = item.value
+			ok = true
+		}
+		return !ok
+	})
+	return
+}
+
+func (r Rule) Apply(input RuleInput) (Level, bool) {
+	return r(input.Names)
+}
+
+func (r Rule) namesOnly() bool {
+	return true
+}
+
+// Implemented by rules that add behaviour to another rule.
+type ruleWrapper interface {
+	unwrapRule() InputRule
+}
+
+// Finds the first rule in the chain of wrapped rules starting at r that is a T.
+func ruleAs[T InputRule](r InputRule) (t T, ok bool) {
+	for r != nil {
+		t, ok = r.(T)
+		if ok {
+			return
+		}
+		w, isWrapper := r.(ruleWrapper)
+		if !isWrapper {
+			return
+		}
+		r = w.unwrapRule()
 	}
+	return
+}
+
+// Implemented by rules that can report that they only depend on the names of a message. The
+// results of those rules can be cached per call site.
+type namesOnlyRule interface {
+	namesOnly() bool
+}
+
+func ruleIsNamesOnly(r InputRule) bool {
+	nor, ok := r.(namesOnlyRule)
+	return ok && nor.namesOnly()
+}
+
+// Adapts a function over all the details of a message, including its values, to an InputRule.
+type RuleInputFunc func(input RuleInput) (level Level, matched bool)
+
+func (f RuleInputFunc) Apply(input RuleInput) (Level, bool) {
+	return f(input)
+}
+
+func alwaysLevel(level Level) InputRule {
+	return Rule(func(names []string) (Level, bool) {
+		return level, true
+	})
 }
 
-func stringSliceContains(s string, ss []string) bool {
-	for _, sss := range ss {
-		if strings.Contains(sss, s) {
+// Matches a single name of a message.
+type nameMatcher func(name string) bool
+
+// A component of a rule filter. A message matches the term if any of its names (or values for a
+// value term) match, or if none do when the term is negated.
+type filterTerm struct {
+	// The term in GO_LOG syntax, without any negation.
+	text   string
+	negate bool
+	match  nameMatcher
+	// If set, the term applies to this component of the Origin instead of all the names.
+	component originComponent
+	// If set, the term applies to the message values instead of the names.
+	value *valueTerm
+}
+
+// Matches key-value items added to a message, such as with [Msg.With].
+type valueTerm struct {
+	key string
+	// Matches the value formatted with fmt.Sprint. If nil, any item with the key matches.
+	match nameMatcher
+}
+
+func (t valueTerm) matches(input RuleInput) (matched bool) {
+	input.Values(func(v interface{}) bool {
+		item, ok := v.(item)
+		if !ok || fmt.Sprint(item.key) != t.key {
 			return true
 		}
+		matched = t.match == nil || t.match(fmt.Sprint(item.value))
+		return !matched
+	})
+	return
+}
+
+func (t filterTerm) String() string {
+	if t.negate {
+		return "!" + t.text
+	}
+	return t.text
+}
+
+func (t filterTerm) dnf() [][]filterTerm {
+	return [][]filterTerm{{t}}
+}
+
+func (t filterTerm) Matches(input RuleInput) bool {
+	if t.value != nil {
+		return t.value.matches(input) != t.negate
+	}
+	if t.component != nil {
+		return t.component(input.Origin, t.match) != t.negate
+	}
+	for _, name := range input.Names {
+		if t.match(name) {
+			return !t.negate
+		}
+	}
+	return t.negate
+}
+
+// A rule that applies a level to messages that match a filter. This is the form of rules parsed
+// from GO_LOG syntax, and built with MinLevel.
+type filterRule struct {
+	// The GO_LOG syntax the rule was parsed from, if it was.
+	text   string
+	filter Filter
+	level  Level
+}
+
+// Returns the rule in GO_LOG syntax. If the filter needs more than one GO_LOG rule to express, they
+// are separated by ",".
+func (r filterRule) String() string {
+	if r.text != "" {
+		return r.text
+	}
+	var rules []string
+	for _, conj := range r.filter.dnf() {
+		rule := formatConjunction(conj)
+		switch {
+		case r.level == Disabled:
+			rule += "="
+		case !r.level.isNotSet():
+			rule += "=" + strings.ToLower(r.level.LogString())
+		}
+		rules = append(rules, rule)
+	}
+	return strings.Join(rules, ",")
+}
+
+func (r filterRule) Apply(input RuleInput) (_ Level, matched bool) {
+	if !r.filter.Matches(input) {
+		return
+	}
+	return r.level, true
+}
+
+func (r filterRule) namesOnly() bool {
+	for _, conj := range r.filter.dnf() {
+		for _, t := range conj {
+			if t.value != nil {
+				return false
+			}
+		}
 	}
-	return false
+	return true
 }
 
-func containsAllNames(all []string, level Level) Rule {
-	return func(names []string) (_ Level, matched bool) {
-		for _, s := range all {
-			//log.Println(s, all, names)
-			if !stringSliceContains(s, names) {
+func parseFilterTerm(s string) (term filterTerm, err error) {
+	if strings.HasPrefix(s, "!") {
+		term.negate = true
+		s = s[1:]
+	}
+	term.text = s
+	if s == "" {
+		err = errors.New("empty name")
+		return
+	}
+	if strings.HasPrefix(s, "{") {
+		term.value, err = parseValueTerm(s)
+		return
+	}
+	if prefix, pattern, ok := strings.Cut(s, ":"); ok {
+		if component, ok := originComponents[prefix]; ok {
+			if pattern == "" {
+				err = errors.New("empty pattern")
 				return
 			}
+			term.component = component
+			if prefix == "file" && strings.Contains(pattern, ":") {
+				term.component = originFileLine
+			}
+			term.match, err = parseMatcher(pattern, func(name string) bool {
+				return name == pattern
+			})
+			return
+		}
+	}
+	term.match, err = parseMatcher(s, func(name string) bool {
+		return strings.Contains(name, s)
+	})
+	return
+}
+
+// Applies a matcher to a component of an Origin.
+type originComponent func(o Origin, match nameMatcher) bool
+
+// The components that can be selected by prefixing a term with the key and ":".
+var originComponents = map[string]originComponent{
+	"name": func(o Origin, match nameMatcher) bool {
+		for _, name := range o.LoggerNames {
+			if match(name) {
+				return true
+			}
+		}
+		return false
+	},
+	"pkg": func(o Origin, match nameMatcher) bool {
+		return match(o.Package)
+	},
+	"func": func(o Origin, match nameMatcher) bool {
+		return match(o.Function)
+	},
+	"file": func(o Origin, match nameMatcher) bool {
+		return match(o.File)
+	},
+}
+
+// Used for "file:" terms that include a line number.
+func originFileLine(o Origin, match nameMatcher) bool {
+	return match(o.FileLine())
+}
+
+// Parses a regexp or glob matcher, or returns plain if s is neither.
+func parseMatcher(s string, plain nameMatcher) (nameMatcher, error) {
+	switch {
+	case strings.HasPrefix(s, "~"):
+		re, err := regexp.Compile(s[1:])
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	case strings.ContainsAny(s, "*?"):
+		return globMatcher(s), nil
+	default:
+		return plain, nil
+	}
+}
+
+// Parses "{key}" or "{key=value}". Unlike names, a plain value must match exactly.
+func parseValueTerm(s string) (*valueTerm, error) {
+	if !strings.HasSuffix(s, "}") {
+		return nil, errors.New("missing closing brace")
+	}
+	key, value, hasValue := strings.Cut(s[1:len(s)-1], "=")
+	if key == "" {
+		return nil, errors.New("empty key")
+	}
+	term := valueTerm{key: key}
+	if hasValue {
+		var err error
+		term.match, err = parseMatcher(value, func(formatted string) bool {
+			return formatted == value
+		})
+		if err != nil {
+			return nil, err
 		}
-		return level, true
 	}
+	return &term, nil
 }
 
-func parseRuleString(s string) (_ Rule, ok bool, _ error) {
+// Returns a matcher for the whole of a name, where "*" matches any sequence of characters
+// (including "/"), and "?" matches any single character.
+func globMatcher(glob string) nameMatcher {
+	return regexp.MustCompile(globRegexp(glob)).MatchString
+}
+
+// Returns the anchored regexp equivalent to glob.
+func globRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteByte('^')
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteByte('.')
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteByte('$')
+	return sb.String()
+}
+
+func parseFilter(s string) (Filter, error) {
+	if s == "*" {
+		return MatchAny(), nil
+	}
+	var terms andFilter
+	for _, termStr := range splitOutsideBraces(s, "+", -1) {
+		term, err := parseFilterTerm(termStr)
+		if err != nil {
+			return nil, fmt.Errorf("parsing filter term %q: %w", termStr, err)
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+func parseRuleString(s string) (_ InputRule, ok bool, _ error) {
 	if s == "" {
 		return
 	}
-	ss := strings.SplitN(s, "=", 2)
+	options := splitOutsideBraces(s, "@", -1)
+	filterAndLevel, sample, hasSample := cutLastOutsideBraces(options[0], "%")
+	rule, err := parseFilterAndLevel(s, filterAndLevel)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, isDefault := rule.(defaultLevelRule); isDefault && (hasSample || slices.ContainsFunc(options[1:], isRateOption)) {
+		return nil, false, errors.New("default level rules can't be sampled or rate limited")
+	}
+	if hasSample {
+		rule, err = parseSampling(rule, sample)
+		if err != nil {
+			return nil, false, fmt.Errorf("parsing sampling %q: %w", sample, err)
+		}
+	}
+	for _, opt := range options[1:] {
+		rule, err = applyRuleOption(rule, opt)
+		if err != nil {
+			return nil, false, fmt.Errorf("parsing option %q: %w", opt, err)
+		}
+	}
+	return rule, true, nil
+}
+
+// Wraps rule with the behaviour given by an "@" option.
+func applyRuleOption(rule InputRule, opt string) (InputRule, error) {
+	if isRateOption(opt) {
+		return parseRateLimit(rule, opt)
+	}
+	d, err := time.ParseDuration(opt)
+	if err != nil {
+		return nil, err
+	}
+	er := expireRuleAfter(rule, d)
+	er.option = opt
+	return er, nil
+}
+
+func isRateOption(opt string) bool {
+	return strings.Contains(opt, "/")
+}
+
+// Parses a rule without any "@" suffixes. text is the full text of the rule.
+func parseFilterAndLevel(text, s string) (InputRule, error) {
+	ss := splitOutsideBraces(s, "=", 2)
+	if filterText, ok := strings.CutSuffix(ss[0], defaultLevelSuffix); ok {
+		ss = append(ss, "")
+		return parseDefaultLevelRule(text, filterText, ss[1])
+	}
 	level := NotSet
-	var names []string
-	if ss[0] != "*" {
-		names = strings.Split(ss[0], "+")
+	filter, err := parseFilter(ss[0])
+	if err != nil {
+		return nil, err
 	}
 	if len(ss) > 1 {
 		var ok bool
@@ -56,20 +421,69 @@ func parseRuleString(s string) (_ Rule, ok bool, _ error) {
 			level = Disabled
 		}
 		if err != nil {
-			return nil, false, fmt.Errorf("parsing level %q: %w", ss[1], err)
+			return nil, fmt.Errorf("parsing level %q: %w", ss[1], err)
 		}
 	}
-	return containsAllNames(names, level), true, nil
+	return filterRule{text, filter, level}, nil
+}
+
+// Like strings.SplitN, but ignores separators inside value terms. Only a "{" that starts a term
+// opens a value term, so braces in other terms, such as regexps, don't hide separators.
+func splitOutsideBraces(s, sep string, n int) (ss []string) {
+	depth := 0
+	start := 0
+	for i := 0; i < len(s) && len(ss)+1 != n; i++ {
+		switch {
+		case s[i] == '{' && (depth > 0 || i == 0 || strings.ContainsRune("+!,", rune(s[i-1]))):
+			depth++
+		case s[i] == '}' && depth > 0:
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			ss = append(ss, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(ss, s[start:])
+}
+
+// Like strings.Cut, but at the last sep that isn't inside a value term.
+func cutLastOutsideBraces(s, sep string) (before, after string, found bool) {
+	ss := splitOutsideBraces(s, sep, -1)
+	if len(ss) == 1 {
+		return s, "", false
+	}
+	after = ss[len(ss)-1]
+	return s[:len(s)-len(after)-len(sep)], after, true
 }
 
-func parseEnvRules() (rules []Rule, err error) {
-	rulesStr := os.Getenv(EnvRules)
+// An error parsing one of a sequence of rules.
+type RuleSyntaxError struct {
+	// The text of the rule that failed to parse.
+	Rule string
+	// The byte offset of the rule in the text being parsed.
+	Offset int
+	Err    error
+}
+
+func (e *RuleSyntaxError) Error() string {
+	return fmt.Sprintf("parsing rule %q at offset %v: %v", e.Rule, e.Offset, e.Err)
+}
+
+func (e *RuleSyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Parses rules in the syntax used by the GO_LOG environment variable.
+func parseRules(rulesStr string) (rules []InputRule, err error) {
 	ruleStrs := strings.Split(rulesStr, ",")
+	offset := 0
 	for _, ruleStr := range ruleStrs {
 		rule, ok, err := parseRuleString(ruleStr)
 		if err != nil {
-			return nil, fmt.Errorf("parsing rule %q: %w", ruleStr, err)
+			return nil, &RuleSyntaxError{ruleStr, offset, err}
 		}
+		offset += len(ruleStr) + len(",")
 		if !ok {
 			continue
 		}
@@ -87,17 +501,14 @@ func levelFromString(s string) (level Level, ok bool, err error) {
 	return
 }
 
-func levelFromRules(names []string) (level Level, ok bool) {
-	defer func() {
-		reportLevelFromRules(level, ok, names)
-	}()
+// Returns the index of the rule that determines the level for input, or -1 if none match.
+func (rs *ruleSet) match(input RuleInput) (index int, level Level) {
 	// Later rules take precedence, so work backwards
-	for i := len(rules) - 1; i >= 0; i-- {
-		r := rules[i]
-		level, ok = r(names)
+	for i := len(rs.rules) - 1; i >= 0; i-- {
+		level, ok := rs.rules[i].Apply(input)
 		if ok {
-			return
+			return i, level
 		}
 	}
-	return
+	return -1, NotSet
 }
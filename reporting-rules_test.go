@@ -1,7 +1,12 @@
 package log
 
 import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 )
@@ -9,15 +14,449 @@ import (
 func TestRepeatReportedNames(t *testing.T) {
 	var a reportedNamesType
 	c := qt.New(t)
-	c.Assert(a.putReport([]string{"bunny"}), qt.IsTrue)
-	c.Assert(a.putReport([]string{"bunny"}), qt.IsFalse)
-	c.Assert(a.putReport([]string{"bunny", "foo", "foo"}), qt.IsTrue)
-	c.Assert(a.putReport([]string{"bunny", "foo", "foo"}), qt.IsFalse)
-	c.Assert(a.putReport([]string{"bunny", "foo"}), qt.IsTrue)
-	c.Assert(a.putReport([]string{"bunny", "foo", "bar"}), qt.IsTrue)
-	c.Assert(a.putReport([]string{"bunny", "foo", "bar"}), qt.IsFalse)
-	c.Assert(a.putReport([]string{"bunny", "foo"}), qt.IsFalse)
-	c.Assert(a.putReport([]string{"bunny"}), qt.IsFalse)
-	c.Assert(a.putReport(nil), qt.IsTrue)
-	c.Assert(a.putReport(nil), qt.IsFalse)
+	putReport := func(names []string) bool {
+		_, added := a.put(names, Origin{LoggerNames: names}, NotSet, nil)
+		return added
+	}
+	c.Assert(putReport([]string{"bunny"}), qt.IsTrue)
+	c.Assert(putReport([]string{"bunny"}), qt.IsFalse)
+	c.Assert(putReport([]string{"bunny", "foo", "foo"}), qt.IsTrue)
+	c.Assert(putReport([]string{"bunny", "foo", "foo"}), qt.IsFalse)
+	c.Assert(putReport([]string{"bunny", "foo"}), qt.IsTrue)
+	c.Assert(putReport([]string{"bunny", "foo", "bar"}), qt.IsTrue)
+	c.Assert(putReport([]string{"bunny", "foo", "bar"}), qt.IsFalse)
+	c.Assert(putReport([]string{"bunny", "foo"}), qt.IsFalse)
+	c.Assert(putReport([]string{"bunny"}), qt.IsFalse)
+	c.Assert(putReport(nil), qt.IsTrue)
+	c.Assert(putReport(nil), qt.IsFalse)
+}
+
+func TestSetRules(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	h, takeRecord := newRecordTaker()
+	l := NewLogger("set-rules-test").WithFilterLevel(Warning)
+	l.SetHandlers(h)
+	logDebug := func() bool {
+		l.Levelf(Debug, "hello")
+		_, ok := takeRecord()
+		return ok
+	}
+	c.Check(logDebug(), qt.IsFalse)
+	c.Assert(SetRules("set-rules-test=debug"), qt.IsNil)
+	c.Check(logDebug(), qt.IsTrue)
+	c.Check(SetRules("set-rules-test=bogus"), qt.IsNotNil)
+	// A bad rule string leaves the existing rules in place.
+	c.Check(logDebug(), qt.IsTrue)
+	AddRule(Rule(func(names []string) (Level, bool) {
+		return Info, true
+	}))
+	c.Check(Rules(), qt.HasLen, 2)
+	c.Check(logDebug(), qt.IsFalse)
+}
+
+func TestParseRuleFilters(t *testing.T) {
+	c := qt.New(t)
+	check := func(rule string, names []string, expected bool) {
+		c.Helper()
+		r, ok, err := parseRuleString(rule)
+		c.Assert(err, qt.IsNil)
+		c.Assert(ok, qt.IsTrue)
+		_, matched := r.Apply(RuleInput{Names: names})
+		c.Check(matched, qt.Equals, expected, qt.Commentf("rule %q, names %q", rule, names))
+	}
+	torrent := []string{"github.com/anacrolix/torrent", "peer.go:42"}
+	webseed := []string{"github.com/anacrolix/torrent/webseed", "client.go:7"}
+	check("anacrolix/torrent", torrent, true)
+	check("anacrolix/torrent", webseed, true)
+	check("github.com/anacrolix/torrent", webseed, true)
+	check("github.com/anacrolix/torrent/*", torrent, false)
+	check("github.com/anacrolix/torrent/*", webseed, true)
+	check("*/torrent", torrent, true)
+	check("*/torrent", webseed, false)
+	check("peer.go:4?", torrent, true)
+	check("~^github.com/anacrolix/torrent$", torrent, true)
+	check("~^github.com/anacrolix/torrent$", webseed, false)
+	check("!webseed", torrent, true)
+	check("!webseed", webseed, false)
+	check("torrent+!webseed", webseed, false)
+	check("*", nil, true)
+	peerMethod := []string{"github.com/anacrolix/torrent", "(*Peer).onReadRequest", "peer.go:42"}
+	check("(*Peer).onReadRequest", peerMethod, true)
+	check("onReadRequest", peerMethod, true)
+	check("(*Peer).onReadRequest", torrent, false)
+	for _, bad := range []string{"a++b", "!", "~(", "a+!=debug"} {
+		_, _, err := parseRuleString(bad)
+		c.Check(err, qt.IsNotNil, qt.Commentf("%q", bad))
+	}
+	_, err := parseRules("a,~[")
+	c.Check(err, qt.ErrorMatches, `parsing rule "~\[" at offset 2: parsing filter term "~\[": .*`)
+}
+
+func TestParseRuleValueFilters(t *testing.T) {
+	c := qt.New(t)
+	check := func(rule string, msg Msg, expected bool) {
+		c.Helper()
+		r, ok, err := parseRuleString(rule)
+		c.Assert(err, qt.IsNil)
+		c.Assert(ok, qt.IsTrue)
+		_, matched := r.Apply(RuleInput{Names: []string{"torrent"}, msg: msg})
+		c.Check(matched, qt.Equals, expected, qt.Commentf("rule %q", rule))
+	}
+	peer := Str("hello").With("peer", "1.2.3.4:6881").With("infohash", 42)
+	check("{peer=1.2.3.4:6881}=debug", peer, true)
+	check("torrent+{peer=1.2.3.4:6881}=debug", peer, true)
+	check("{peer=1.2.3.4:688}=debug", peer, false)
+	check("{peer=1.2.3.4:*}=debug", peer, true)
+	check("{peer=~^1\\.2\\.}", peer, true)
+	check("{infohash=42}", peer, true)
+	check("{infohash}", peer, true)
+	check("{infohash}", Str("hello"), false)
+	check("!{peer=5.6.7.8:1}", peer, true)
+	for _, bad := range []string{"{peer", "{}", "{=x}"} {
+		_, _, err := parseRuleString(bad)
+		c.Check(err, qt.IsNotNil, qt.Commentf("%q", bad))
+	}
+	// Braces in other terms don't hide the separators after them.
+	r, _, err := parseRuleString("~a{2=debug")
+	c.Assert(err, qt.IsNil)
+	level, matched := r.Apply(RuleInput{Names: []string{"a{2"}})
+	c.Check(matched, qt.IsTrue)
+	c.Check(level, qt.Equals, Debug)
+}
+
+func TestCallSiteCacheWithValueRules(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	c.Assert(SetRules("{peer=a}=debug"), qt.IsNil)
+	h, takeRecord := newRecordTaker()
+	l := NewLogger("call-site-cache-test").WithFilterLevel(Warning)
+	l.SetHandlers(h)
+	logged := func(peer string) bool {
+		// Every call is from the same call site, so a cached result must not apply to all values.
+		l.WithValues(item{"peer", peer}).Levelf(Debug, "hello")
+		_, ok := takeRecord()
+		return ok
+	}
+	c.Check(logged("a"), qt.IsTrue)
+	c.Check(logged("b"), qt.IsFalse)
+	c.Check(logged("a"), qt.IsTrue)
+}
+
+func TestExplain(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	c.Assert(SetRules("torrent=info,webseed=debug"), qt.IsNil)
+	l := NewLogger().WithFilterLevel(Error)
+	e := l.Explain([]string{"github.com/anacrolix/torrent/webseed", "client.go:7"})
+	c.Check(e.RuleIndex, qt.Equals, 1)
+	c.Check(e.Rule, qt.Equals, "webseed=debug")
+	c.Check(e.Level, qt.Equals, Debug)
+	c.Check(e.UsedFilterLevel, qt.IsFalse)
+	e = l.Explain([]string{"github.com/anacrolix/torrent", "peer.go:42"})
+	c.Check(e.RuleIndex, qt.Equals, 0)
+	c.Check(e.Level, qt.Equals, Info)
+	e = l.Explain([]string{"github.com/anacrolix/dht"})
+	c.Check(e.RuleIndex, qt.Equals, -1)
+	c.Check(e.Level, qt.Equals, Error)
+	c.Check(e.UsedFilterLevel, qt.IsTrue)
+}
+
+func TestListSeenNames(t *testing.T) {
+	c := qt.New(t)
+	// The registry is global, so look for names by logger name, and compare counts with what was
+	// there before, in case the test has already run.
+	find := func(loggerName string) (ret SeenNames, ok bool) {
+		for _, sn := range ListSeenNames() {
+			if slices.Equal(sn.LoggerNames, []string{loggerName}) {
+				c.Assert(ok, qt.IsFalse, qt.Commentf("duplicate names for %q", loggerName))
+				ret, ok = sn, true
+			}
+		}
+		return
+	}
+	before, _ := find("list-seen-names-test")
+	l := NewLogger("list-seen-names-test").WithFilterLevel(Info)
+	l.SetHandlers(DiscardHandler)
+	logDebug := func() {
+		l.Levelf(Debug, "hello")
+	}
+	for i := 0; i < 3; i++ {
+		logDebug()
+	}
+	sn, ok := find("list-seen-names-test")
+	c.Assert(ok, qt.IsTrue)
+	c.Check(sn.Package, qt.Equals, "github.com/anacrolix/log")
+	c.Check(sn.Function, qt.Equals, "TestListSeenNames.func2")
+	c.Check(sn.File, qt.Equals, "reporting-rules_test.go")
+	c.Check(sn.Hits-before.Hits, qt.Equals, uint64(3))
+	c.Check(sn.LastHandled, qt.Equals, before.LastHandled)
+	c.Check(sn.Level, qt.Equals, Info)
+	// Handled messages update the time.
+	defer ReplaceRules(ReplaceRules(nil))
+	c.Assert(SetRules("list-seen-names-test=debug"), qt.IsNil)
+	logDebug()
+	sn, _ = find("list-seen-names-test")
+	c.Check(sn.LastHandled.IsZero(), qt.IsFalse)
+	c.Check(sn.Level, qt.Equals, Debug)
+	// The level follows changes to the filter level of Default.
+	defer defaultFilterLevel.Set(defaultFilterLevel.Level())
+	defaultFilterLevel.Set(Warning)
+	Default.WithNames("list-seen-names-default-test").Levelf(Debug, "hello")
+	defaultFilterLevel.Set(Debug)
+	sn, ok = find("list-seen-names-default-test")
+	c.Assert(ok, qt.IsTrue)
+	c.Check(sn.Level, qt.Equals, Debug)
+}
+
+func TestExpiringRules(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	now := time.Now()
+	defer func(orig func() time.Time) { timeNow = orig }(timeNow)
+	timeNow = func() time.Time { return now }
+	c.Assert(SetRules("torrent=debug@10m,dht=info"), qt.IsNil)
+	AddRuleFor(alwaysLevel(Error), time.Hour)
+	names := []string{"torrent"}
+	c.Check(Rules(), qt.HasLen, 3)
+	c.Check(Explain(names).Level, qt.Equals, Error)
+	// Expiring rules are rendered with the time remaining, so they can be parsed again.
+	c.Check(describeRule(Rules()[0]), qt.Equals, "torrent=debug@10m0s")
+	now = now.Add(4 * time.Minute)
+	c.Check(describeRule(Rules()[0]), qt.Equals, "torrent=debug@6m0s")
+	c.Check(describeRule(expireRuleAfter(MinLevel(Or(NameContains("a"), NameContains("b")), Debug), time.Minute)), qt.Equals, "a=dbg@1m0s,b=dbg@1m0s")
+	now = now.Add(time.Hour)
+	c.Check(Explain(names).UsedFilterLevel, qt.IsTrue)
+	c.Check(Rules(), qt.HasLen, 1)
+	_, _, err := parseRuleString("torrent=debug@10 minutes")
+	c.Check(err, qt.IsNotNil)
+}
+
+func TestRateLimitedRules(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	c.Assert(SetRules("rate-limit-test=info@2/100ms"), qt.IsNil)
+	rs := make(chan Record, 10)
+	l := NewLogger("rate-limit-test").WithFilterLevel(Disabled)
+	l.SetHandlers(chanHandler{rs})
+	for i := 0; i < 5; i++ {
+		l.Levelf(Info, "hello")
+	}
+	c.Check(len(rs), qt.Equals, 2)
+	<-rs
+	<-rs
+	select {
+	case r := <-rs:
+		c.Check(r.Text(), qt.Matches, `suppressed 3 messages from rate-limit-test .*`)
+		c.Check(r.Level, qt.Equals, Info)
+	case <-time.After(time.Second):
+		c.Fatal("no summary")
+	}
+	for _, bad := range []string{"a=info@0/s", "a=info@x/s", "a=info@5/", "a=info@5/-1s"} {
+		_, _, err := parseRuleString(bad)
+		c.Check(err, qt.IsNotNil, qt.Commentf("%q", bad))
+	}
+}
+
+func TestSampling(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	defer samplingSeed.Store(samplingSeed.Load())
+	SetSamplingSeed(42)
+	decisions := func() (ret []bool) {
+		for n := uint64(0); n < 1000; n++ {
+			ret = append(ret, keepSample(0.1, 7, n))
+		}
+		return
+	}
+	first := decisions()
+	c.Check(decisions(), qt.DeepEquals, first)
+	kept := 0
+	for _, keep := range first {
+		if keep {
+			kept++
+		}
+	}
+	c.Check(kept > 50 && kept < 150, qt.IsTrue, qt.Commentf("kept %v", kept))
+
+	c.Assert(SetRules("sampling-test=debug%4"), qt.IsNil)
+	rs := make(chan Record, 1000)
+	l := NewLogger("sampling-test").WithFilterLevel(Disabled).WithSampling(0.5)
+	l.SetHandlers(chanHandler{rs})
+	for i := 0; i < 1000; i++ {
+		l.Levelf(Debug, "hello")
+	}
+	c.Check(len(rs) > 50 && len(rs) < 250, qt.IsTrue, qt.Commentf("kept %v", len(rs)))
+	r := <-rs
+	c.Check(r.Msg.HasValue(item{SampleRateKey, 0.125}), qt.IsTrue)
+
+	for _, bad := range []string{"a=debug%0", "a=debug%x", "a=debug%-1"} {
+		_, _, err := parseRuleString(bad)
+		c.Check(err, qt.IsNotNil, qt.Commentf("%q", bad))
+	}
+}
+
+func TestParseRuleOriginFilters(t *testing.T) {
+	c := qt.New(t)
+	origin := Origin{
+		LoggerNames: []string{"webseed"},
+		Package:     "github.com/anacrolix/torrent",
+		Function:    "(*Peer).onReadRequest",
+		File:        "peer.go",
+		Line:        42,
+	}
+	check := func(rule string, expected bool) {
+		c.Helper()
+		r, ok, err := parseRuleString(rule)
+		c.Assert(err, qt.IsNil)
+		c.Assert(ok, qt.IsTrue)
+		_, matched := r.Apply(RuleInput{Names: origin.Names(), Origin: origin})
+		c.Check(matched, qt.Equals, expected, qt.Commentf("rule %q", rule))
+	}
+	check("pkg:github.com/anacrolix/torrent", true)
+	check("pkg:anacrolix/torrent", false)
+	check("pkg:*/torrent", true)
+	check("!pkg:github.com/anacrolix/torrent", false)
+	check("name:webseed", true)
+	check("name:torrent", false)
+	check("func:(*Peer).onReadRequest", true)
+	check("func:~onRead", true)
+	check("file:peer.go", true)
+	check("file:peer.go:42", true)
+	check("file:peer.go:43", false)
+	check("pkg:github.com/anacrolix/torrent+!name:webseed", false)
+	// Unknown prefixes are substrings as before.
+	check("peer.go:42", true)
+	_, _, err := parseRuleString("pkg:")
+	c.Check(err, qt.IsNotNil)
+}
+
+func TestRuleBuilder(t *testing.T) {
+	c := qt.New(t)
+	origin := Origin{
+		LoggerNames: []string{"webseed"},
+		Package:     "github.com/anacrolix/torrent",
+		Function:    "(*Peer).onReadRequest",
+		File:        "peer.go",
+		Line:        42,
+	}
+	input := RuleInput{Names: origin.Names(), Origin: origin}
+	check := func(rule InputRule, text string, expected bool) {
+		c.Helper()
+		c.Check(describeRule(rule), qt.Equals, text)
+		_, matched := rule.Apply(input)
+		c.Check(matched, qt.Equals, expected, qt.Commentf("rule %q", text))
+		// The rendered rules are equivalent when parsed.
+		parsed, err := parseRules(text)
+		c.Assert(err, qt.IsNil)
+		_, level := newRuleSet(parsed).match(input)
+		matchedLevel, _ := rule.Apply(input)
+		c.Check(level, qt.Equals, matchedLevel, qt.Commentf("rule %q", text))
+	}
+	check(MinLevel(MatchAny(), Debug), "*=dbg", true)
+	check(MinLevel(Package("github.com/anacrolix/torrent"), Info), "pkg:github.com/anacrolix/torrent=inf", true)
+	check(MinLevel(And(LoggerName("webseed"), FileLine("peer.go", 42)), Disabled), "name:webseed+file:peer.go:42=", true)
+	check(MinLevel(And(NameContains("torrent"), Not(Function("(*Peer).onReadRequest"))), Warning), `torrent+!func:~^\(\*Peer\)\.onReadRequest$=wrn`, false)
+	check(MinLevel(Or(NameGlob("*peer*"), File("conn.go")), Error), "*peer*=err,file:conn.go=err", true)
+	check(MinLevel(Not(Or(NameContains("dht"), NameContains("webseed"))), Debug), "!dht+!webseed=dbg", false)
+	check(MinLevel(Not(And(NameContains("dht"), NameContains("webseed"))), Debug), "!dht=dbg,!webseed=dbg", true)
+	check(MinLevel(KeyValue("peer", "1.2.3.4"), Debug), "{peer=1.2.3.4}=dbg", false)
+	// Text that GO_LOG would otherwise parse as something else.
+	check(MinLevel(NameContains("web*"), Debug), `~web\*=dbg`, false)
+	check(MinLevel(NameContains("!webseed"), Debug), "~!webseed=dbg", false)
+	check(MinLevel(NameContains("~webseed"), Debug), "~~webseed=dbg", false)
+	check(MinLevel(NameContains("pkg:github.com"), Debug), "~pkg:github\\.com=dbg", false)
+	check(MinLevel(NameGlob("webseed"), Debug), "~^webseed$=dbg", true)
+	check(MinLevel(NameGlob("web"), Debug), "~^web$=dbg", false)
+	check(MinLevel(NameGlob("pkg:*"), Debug), "~^pkg:.*$=dbg", false)
+	check(MinLevel(Package("github.com/*"), Debug), `pkg:~^github\.com/\*$=dbg`, false)
+	check(MinLevel(Not(MatchAny()), Debug), "!pkg:*=dbg", false)
+	check(MinLevel(Or(), Debug), "!pkg:*=dbg", false)
+	check(MinLevel(Not(Not(MatchAny())), Debug), "pkg:*=dbg", true)
+	// Text that can't be expressed.
+	for _, f := range []func(){
+		func() { NameContains("a,b") },
+		func() { NameContains("a=b") },
+		func() { NameContains("a+b") },
+		func() { NameContains("{a}") },
+		func() { NameContains("") },
+		func() { NameGlob("a,*") },
+		func() { NameRegexp(regexp.MustCompile("a+b")) },
+		func() { NameRegexp(regexp.MustCompile("a:default")) },
+		func() { Package("x:default") },
+		func() { File("a:b.go") },
+		func() { FileLine("a%b.go", 1) },
+		func() { KeyValue("a=b", "c") },
+		func() { KeyValue("a", "}") },
+	} {
+		c.Check(f, qt.PanicMatches, ".+")
+	}
+}
+
+func TestDefaultLevelRules(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	c.Assert(SetRules("dht:default=debug,torrent:default=error"), qt.IsNil)
+	h, takeRecord := newRecordTaker()
+	logged := func(name string) (Level, bool) {
+		l := NewLogger(name).WithDefaultLevel(Info).WithFilterLevel(Info)
+		l.SetHandlers(h)
+		l.Print("hello")
+		r, ok := takeRecord()
+		return r.Level, ok
+	}
+	_, ok := logged("dht")
+	c.Check(ok, qt.IsFalse)
+	level, ok := logged("torrent")
+	c.Check(ok, qt.IsTrue)
+	c.Check(level, qt.Equals, Error)
+	level, ok = logged("webseed")
+	c.Check(ok, qt.IsTrue)
+	c.Check(level, qt.Equals, Info)
+	// Default level rules don't affect filtering.
+	c.Check(Explain([]string{"dht"}).RuleIndex, qt.Equals, -1)
+	c.Check(Explain([]string{"dht"}).DefaultLevel, qt.Equals, Debug)
+	c.Check(describeRule(DefaultLevel(Or(NameContains("dht"), Package("net")), Debug)), qt.Equals, "dht:default=dbg,pkg:net:default=dbg")
+	for _, rule := range []string{"dht:default", "dht:default=", "dht:default=debug%10", "dht:default=debug@5/s"} {
+		c.Check(SetRules(rule), qt.IsNotNil, qt.Commentf("rule %q", rule))
+	}
+	c.Check(SetRules("dht:default=debug@5m"), qt.IsNil)
+}
+
+func TestRulesFile(t *testing.T) {
+	c := qt.New(t)
+	defer ReplaceRules(ReplaceRules(nil))
+	c.Assert(SetRules("torrent=info"), qt.IsNil)
+	path := filepath.Join(t.TempDir(), "log.rules")
+	modTime := time.Now()
+	write := func(contents string) {
+		c.Assert(os.WriteFile(path, []byte(contents), 0o600), qt.IsNil)
+		modTime = modTime.Add(time.Second)
+		c.Assert(os.Chtimes(path, modTime, modTime), qt.IsNil)
+	}
+	ruleStrings := func() (ret []string) {
+		for _, r := range Rules() {
+			ret = append(ret, describeRule(r))
+		}
+		return
+	}
+	write("# Comments and blank lines are ignored.\n\ndht=debug\n  webseed=,peer=warning\n")
+	rf := &rulesFile{path: path}
+	c.Assert(rf.load(), qt.IsNil)
+	c.Check(ruleStrings(), qt.DeepEquals, []string{"torrent=info", "dht=debug", "webseed=", "peer=warning"})
+	AddRule(MinLevel(NameContains("client"), Error))
+	c.Assert(rf.loadIfModified(), qt.IsNil)
+	write("dht=info\n")
+	c.Assert(rf.loadIfModified(), qt.IsNil)
+	c.Check(ruleStrings(), qt.DeepEquals, []string{"torrent=info", "dht=info", "client=err"})
+	// The previous rules are kept if the file fails to parse.
+	write("dht=info\ndht=bogus\n")
+	c.Check(rf.loadIfModified(), qt.ErrorMatches, `.*line 2: .*`)
+	c.Check(ruleStrings(), qt.DeepEquals, []string{"torrent=info", "dht=info", "client=err"})
+	// SetRules keeps the file rules, with lower precedence, and they're reloaded in place.
+	c.Assert(SetRules("webseed=debug"), qt.IsNil)
+	c.Check(ruleStrings(), qt.DeepEquals, []string{"dht=info", "webseed=debug"})
+	write("dht=error\n")
+	c.Assert(rf.loadIfModified(), qt.IsNil)
+	c.Check(ruleStrings(), qt.DeepEquals, []string{"dht=error", "webseed=debug"})
 }
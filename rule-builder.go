@@ -0,0 +1,304 @@
+package log
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Filter selects the messages a rule applies to. Filters are built with the constructors in this
+// package, such as NameContains and Package, and combined with And, Or and Not. A rule is made
+// from a Filter with MinLevel. The constructors panic if given text that can't be expressed in
+// GO_LOG syntax, such as text containing ",".
+type Filter interface {
+	Matches(input RuleInput) bool
+	// Renders the filter in GO_LOG syntax. Filters that can't be expressed as a single GO_LOG
+	// filter, such as from Or, are rendered as several filters separated by ",".
+	String() string
+	// Returns the filter in disjunctive normal form, which is how it's expressed in GO_LOG: Each
+	// conjunction is a filter of terms joined by "+", and the disjunction is a sequence of rules.
+	dnf() [][]filterTerm
+}
+
+// Matches every message. It's "*" in GO_LOG syntax.
+func MatchAny() Filter {
+	return andFilter(nil)
+}
+
+// Matches messages with any name containing s.
+func NameContains(s string) Filter {
+	checkTermText("name", s, nameDelimiters)
+	text := s
+	if strings.ContainsAny(s, "*?") || strings.ContainsAny(s[:1], "!~") || hasComponentPrefix(s) {
+		text = "~" + regexp.QuoteMeta(s)
+	}
+	return filterTerm{
+		text: text,
+		match: func(name string) bool {
+			return strings.Contains(name, s)
+		},
+	}
+}
+
+// Matches messages with any name that matches glob in its entirety. See the package documentation
+// for the glob syntax.
+func NameGlob(glob string) Filter {
+	checkTermText("glob", glob, nameDelimiters)
+	text := glob
+	if !strings.ContainsAny(glob, "*?") || strings.ContainsAny(glob[:1], "!~") || hasComponentPrefix(glob) {
+		// Without wildcards, or with a prefix that changes the meaning of a term, the glob would
+		// be parsed as something else.
+		text = "~" + globRegexp(glob)
+	}
+	return filterTerm{
+		text:  text,
+		match: globMatcher(glob),
+	}
+}
+
+// Matches messages with any name that matches re.
+func NameRegexp(re *regexp.Regexp) Filter {
+	checkTermText("regexp", re.String(), nameDelimiters)
+	return filterTerm{
+		text:  "~" + re.String(),
+		match: re.MatchString,
+	}
+}
+
+// Characters that separate rules, terms, levels and options in GO_LOG syntax, or delimit value
+// terms.
+const nameDelimiters = ",+=%@{}"
+
+// Panics if s is empty or contains any of forbidden, or would be mistaken for a default level rule.
+func checkTermText(what, s, forbidden string) {
+	switch {
+	case s == "":
+		panic(fmt.Sprintf("empty %s", what))
+	case strings.ContainsAny(s, forbidden):
+		panic(fmt.Sprintf("%s %q can't contain any of %q", what, s, forbidden))
+	case strings.HasSuffix(s, defaultLevelSuffix):
+		panic(fmt.Sprintf("%s %q can't end with %q", what, s, defaultLevelSuffix))
+	}
+}
+
+// Whether s would be parsed as a term for a component of the Origin.
+func hasComponentPrefix(s string) bool {
+	prefix, _, ok := strings.Cut(s, ":")
+	_, isComponent := originComponents[prefix]
+	return ok && isComponent
+}
+
+func exactMatcher(s string) nameMatcher {
+	return func(name string) bool {
+		return name == s
+	}
+}
+
+// Returns the pattern for a term that matches exactly s. That's s itself, unless it would be
+// parsed as a glob or regexp.
+func exactPattern(s string) string {
+	if strings.ContainsAny(s, "*?") || strings.HasPrefix(s, "~") {
+		return "~^" + regexp.QuoteMeta(s) + "$"
+	}
+	return s
+}
+
+func originComponentTerm(prefix, value string) filterTerm {
+	checkTermText(prefix, value, nameDelimiters)
+	return filterTerm{
+		text:      prefix + ":" + exactPattern(value),
+		match:     exactMatcher(value),
+		component: originComponents[prefix],
+	}
+}
+
+// Matches messages from a Logger with the given name, such as added by WithNames.
+func LoggerName(name string) Filter {
+	return originComponentTerm("name", name)
+}
+
+// Matches messages logged from the package with the given import path, excluding subpackages.
+func Package(importPath string) Filter {
+	return originComponentTerm("pkg", importPath)
+}
+
+// Matches messages logged from the given function, named without its package path, such as
+// "(*Peer).onReadRequest".
+func Function(name string) Filter {
+	return originComponentTerm("func", name)
+}
+
+// Matches messages logged from files with the given short name.
+func File(name string) Filter {
+	// A ":" would be parsed as the start of a line number.
+	checkTermText("file", name, nameDelimiters+":")
+	return originComponentTerm("file", name)
+}
+
+// Matches messages logged from the given short file name and line.
+func FileLine(file string, line int) Filter {
+	checkTermText("file", file, nameDelimiters+":")
+	fileLine := file + ":" + strconv.Itoa(line)
+	return filterTerm{
+		text:      "file:" + exactPattern(fileLine),
+		match:     exactMatcher(fileLine),
+		component: originFileLine,
+	}
+}
+
+// Matches messages with a value added with a key and value that format as the given strings, such
+// as by Msg.With.
+func KeyValue(key, value string) Filter {
+	switch {
+	case key == "":
+		panic("empty key")
+	case strings.ContainsAny(key, ",={}"):
+		panic(fmt.Sprintf("key %q can't contain any of %q", key, ",={}"))
+	case strings.ContainsAny(value, ",{}"):
+		// Other delimiters are ignored inside the braces of a value term.
+		panic(fmt.Sprintf("value %q can't contain any of %q", value, ",{}"))
+	}
+	return filterTerm{
+		text: "{" + key + "=" + exactPattern(value) + "}",
+		value: &valueTerm{
+			key:   key,
+			match: exactMatcher(value),
+		},
+	}
+}
+
+// Matches messages that match all of the filters.
+func And(filters ...Filter) Filter {
+	return andFilter(filters)
+}
+
+// Matches messages that match any of the filters.
+func Or(filters ...Filter) Filter {
+	return orFilter(filters)
+}
+
+// Matches messages that don't match the filter.
+func Not(filter Filter) Filter {
+	return notFilter{filter}
+}
+
+// A rule that requires messages matching filter to have at least the given level. Disabled
+// prevents them being logged, and NotSet allows all of them.
+func MinLevel(filter Filter, level Level) InputRule {
+	return filterRule{
+		filter: filter,
+		level:  level,
+	}
+}
+
+type andFilter []Filter
+
+func (me andFilter) Matches(input RuleInput) bool {
+	for _, f := range me {
+		if !f.Matches(input) {
+			return false
+		}
+	}
+	return true
+}
+
+func (me andFilter) dnf() [][]filterTerm {
+	// The conjunction of disjunctions is the cross product of their conjunctions.
+	ret := [][]filterTerm{nil}
+	for _, f := range me {
+		var next [][]filterTerm
+		for _, left := range ret {
+			for _, right := range f.dnf() {
+				next = append(next, append(left[:len(left):len(left)], right...))
+			}
+		}
+		ret = next
+	}
+	return ret
+}
+
+func (me andFilter) String() string {
+	return formatDnf(me.dnf())
+}
+
+type orFilter []Filter
+
+func (me orFilter) Matches(input RuleInput) bool {
+	for _, f := range me {
+		if f.Matches(input) {
+			return true
+		}
+	}
+	return false
+}
+
+func (me orFilter) dnf() (ret [][]filterTerm) {
+	for _, f := range me {
+		ret = append(ret, f.dnf()...)
+	}
+	if len(ret) == 0 {
+		// GO_LOG has no empty disjunction, so it needs a term that never matches.
+		ret = [][]filterTerm{{matchNoneTerm}}
+	}
+	return
+}
+
+// Never matches, since every message has a package, even if it's empty. It's what an empty Or, and
+// so Not(MatchAny()), is rendered as.
+var matchNoneTerm = filterTerm{
+	text:      "pkg:*",
+	negate:    true,
+	match:     globMatcher("*"),
+	component: originComponents["pkg"],
+}
+
+func (me orFilter) String() string {
+	return formatDnf(me.dnf())
+}
+
+type notFilter struct {
+	Filter
+}
+
+func (me notFilter) Matches(input RuleInput) bool {
+	return !me.Filter.Matches(input)
+}
+
+func (me notFilter) dnf() [][]filterTerm {
+	// By De Morgan's laws, the negation of a disjunction of conjunctions is a conjunction of
+	// disjunctions of the negated terms.
+	var and andFilter
+	for _, conj := range me.Filter.dnf() {
+		var or orFilter
+		for _, t := range conj {
+			t.negate = !t.negate
+			or = append(or, t)
+		}
+		and = append(and, or)
+	}
+	return and.dnf()
+}
+
+func (me notFilter) String() string {
+	return formatDnf(me.dnf())
+}
+
+func formatConjunction(conj []filterTerm) string {
+	if len(conj) == 0 {
+		return "*"
+	}
+	terms := make([]string, 0, len(conj))
+	for _, t := range conj {
+		terms = append(terms, t.String())
+	}
+	return strings.Join(terms, "+")
+}
+
+func formatDnf(dnf [][]filterTerm) string {
+	filters := make([]string, 0, len(dnf))
+	for _, conj := range dnf {
+		filters = append(filters, formatConjunction(conj))
+	}
+	return strings.Join(filters, ",")
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// An immutable sequence of rules. Changes to the rules in effect are made by swapping in a new
+// ruleSet, so concurrent logging always sees a consistent set without locking.
+type ruleSet struct {
+	rules []InputRule
+	// Unique to each ruleSet, so that results cached from other sets are not reused.
+	generation uint64
+	// Whether the results of the rules only depend on message names, and can be cached per call
+	// site.
+	cacheable bool
+	// When the first rule expires, or the zero Time if none do.
+	expiry time.Time
+}
+
+var (
+	activeRules            atomic.Pointer[ruleSet]
+	ruleSetGenerations     atomic.Uint64
+	emptyRuleSetBeforeInit = newRuleSet(nil)
+)
+
+func newRuleSet(rules []InputRule) *ruleSet {
+	rs := &ruleSet{
+		rules:      rules,
+		generation: ruleSetGenerations.Add(1),
+		cacheable:  true,
+		expiry:     rulesExpiry(rules),
+	}
+	for _, r := range rules {
+		if !ruleIsNamesOnly(r) {
+			rs.cacheable = false
+		}
+	}
+	return rs
+}
+
+// Returns the rules in effect, removing any that have expired. This is safe to call before init.
+func loadRuleSet() *ruleSet {
+	rs := activeRules.Load()
+	if rs == nil {
+		return emptyRuleSetBeforeInit
+	}
+	if !rs.expiry.IsZero() {
+		if now := timeNow(); !now.Before(rs.expiry) {
+			removeExpiredRules(now)
+			rs = activeRules.Load()
+		}
+	}
+	return rs
+}
+
+// Replaces the rules in effect with those parsed from s, which has the same syntax as the
+// environment variable [EnvRules]. The existing rules are left in place if s fails to parse. Rules
+// loaded from the file named by [EnvRulesFile] are kept, but the new rules take precedence over
+// them.
+func SetRules(s string) error {
+	rules, err := parseRules(s)
+	if err != nil {
+		return err
+	}
+	for {
+		old := activeRules.Load()
+		var kept []InputRule
+		for _, r := range old.rulesOrNil() {
+			if _, ok := r.(*fileRule); ok {
+				kept = append(kept, r)
+			}
+		}
+		if activeRules.CompareAndSwap(old, newRuleSet(append(kept, rules...))) {
+			return nil
+		}
+	}
+}
+
+// Replaces the rules in effect, returning the rules that were replaced. This is convenient for
+// restoring rules after temporarily changing them, such as in tests.
+func ReplaceRules(rules []InputRule) (previous []InputRule) {
+	return activeRules.Swap(newRuleSet(slices.Clone(rules))).rulesOrNil()
+}
+
+// Adds a rule that takes precedence over all the rules already in effect.
+func AddRule(r InputRule) {
+	for {
+		old := activeRules.Load()
+		rules := old.rulesOrNil()
+		new := newRuleSet(append(rules[:len(rules):len(rules)], r))
+		if activeRules.CompareAndSwap(old, new) {
+			return
+		}
+	}
+}
+
+// Removes the rules for which remove returns true, and reports whether any were.
+func removeRules(remove func(InputRule) bool) (removed bool) {
+	for {
+		old := activeRules.Load()
+		var rules []InputRule
+		for _, r := range old.rulesOrNil() {
+			if !remove(r) {
+				rules = append(rules, r)
+			}
+		}
+		if len(rules) == len(old.rulesOrNil()) {
+			return false
+		}
+		if activeRules.CompareAndSwap(old, newRuleSet(rules)) {
+			return true
+		}
+	}
+}
+
+// Returns the rules in effect, in order of increasing precedence.
+func Rules() []InputRule {
+	return slices.Clone(loadRuleSet().rules)
+}
+
+func (rs *ruleSet) rulesOrNil() []InputRule {
+	if rs == nil {
+		return nil
+	}
+	return rs.rules
+}
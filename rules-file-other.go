@@ -0,0 +1,10 @@
+//go:build !unix
+
+package log
+
+import (
+	"os"
+)
+
+// There's no conventional reload signal, so the rules file is only reloaded when it's modified.
+func notifyReloadRulesFile(c chan<- os.Signal) {}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Arranges for c to receive when the rules file should be reloaded.
+func notifyReloadRulesFile(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGHUP)
+}
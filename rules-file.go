@@ -0,0 +1,150 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// How often the rules file is checked for changes.
+const rulesFilePollInterval = 5 * time.Second
+
+// A rule loaded from a rules file. Reloading the file replaces these.
+type fileRule struct {
+	InputRule
+}
+
+func (me *fileRule) unwrapRule() InputRule {
+	return me.InputRule
+}
+
+func (me *fileRule) namesOnly() bool {
+	return ruleIsNamesOnly(me.InputRule)
+}
+
+func (me *fileRule) String() string {
+	return describeRule(me.InputRule)
+}
+
+// Parses rules from the contents of a rules file. Each line has rules in the same syntax as the
+// environment variable [EnvRules]. Blank lines and lines starting with "#" are ignored.
+func parseRulesFile(b []byte) (rules []InputRule, err error) {
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for lineNum := 1; s.Scan(); lineNum++ {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		lineRules, err := parseRules(string(line))
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", lineNum, err)
+		}
+		rules = append(rules, lineRules...)
+	}
+	return rules, s.Err()
+}
+
+// A rules file whose rules are merged into the rules in effect.
+type rulesFile struct {
+	path string
+	mu   sync.Mutex
+	// The modification time of the file when it was last loaded.
+	modTime time.Time
+}
+
+// Loads the rules from the file, replacing any previously loaded from it. The rules in effect are
+// unchanged if there's an error.
+func (me *rulesFile) load() error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	fi, err := os.Stat(me.path)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(me.path)
+	if err != nil {
+		return err
+	}
+	// Don't retry a file that failed to parse until it's modified again.
+	me.modTime = fi.ModTime()
+	rules, err := parseRulesFile(b)
+	if err != nil {
+		return fmt.Errorf("parsing %q: %w", me.path, err)
+	}
+	replaceFileRules(rules)
+	return nil
+}
+
+// Loads the file if it has been modified since it was last loaded.
+func (me *rulesFile) loadIfModified() error {
+	fi, err := os.Stat(me.path)
+	if err != nil {
+		return err
+	}
+	me.mu.Lock()
+	modified := !fi.ModTime().Equal(me.modTime)
+	me.mu.Unlock()
+	if !modified {
+		return nil
+	}
+	return me.load()
+}
+
+// Reloads the file when it's modified, or when reload receives. Errors are logged, and the previous
+// rules stay in effect. An error is only logged again once it changes, so a missing file isn't
+// reported on every poll.
+func (me *rulesFile) watch(reload <-chan os.Signal) {
+	ticker := time.NewTicker(rulesFilePollInterval)
+	defer ticker.Stop()
+	var lastErr string
+	for {
+		var err error
+		select {
+		case <-ticker.C:
+			err = me.loadIfModified()
+		case <-reload:
+			err = me.load()
+		}
+		if err == nil {
+			lastErr = ""
+			continue
+		}
+		if err.Error() != lastErr {
+			Default.Levelf(Error, "reloading rules file: %v", err)
+			lastErr = err.Error()
+		}
+	}
+}
+
+// Replaces the rules from a rules file with rules. They take the place of the existing file rules,
+// or take precedence over all rules if there are none.
+func replaceFileRules(rules []InputRule) {
+	for {
+		old := activeRules.Load()
+		var merged []InputRule
+		inserted := false
+		insert := func() {
+			if inserted {
+				return
+			}
+			for _, r := range rules {
+				merged = append(merged, &fileRule{r})
+			}
+			inserted = true
+		}
+		for _, r := range old.rulesOrNil() {
+			if _, ok := r.(*fileRule); ok {
+				insert()
+				continue
+			}
+			merged = append(merged, r)
+		}
+		insert()
+		if activeRules.CompareAndSwap(old, newRuleSet(merged)) {
+			return
+		}
+	}
+}
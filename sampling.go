@@ -0,0 +1,84 @@
+package log
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+)
+
+// The key of the value added to sampled messages, giving the fraction of messages that are kept.
+const SampleRateKey = "sample_rate"
+
+var samplingSeed atomic.Pointer[uint64]
+
+// Makes sampling decisions deterministic for each call site, so that the same messages are kept
+// each time a program is run. The seed can also be given in the environment variable with the key
+// of [EnvSamplingSeed]. Otherwise sampling is random.
+func SetSamplingSeed(seed uint64) {
+	samplingSeed.Store(&seed)
+}
+
+// A rule that keeps only a fraction of the messages it allows.
+type sampledRule struct {
+	InputRule
+	rate float64
+}
+
+// Parses the sampling part of a rule, which is either N meaning 1 in N, or a fraction less than 1.
+func parseSampling(rule InputRule, s string) (InputRule, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	if !(f > 0) || math.IsInf(f, 0) {
+		return nil, errors.New("must be positive")
+	}
+	rate := f
+	if f >= 1 {
+		rate = 1 / f
+	}
+	return &sampledRule{rule, rate}, nil
+}
+
+func (me *sampledRule) unwrapRule() InputRule {
+	return me.InputRule
+}
+
+func (me *sampledRule) namesOnly() bool {
+	return ruleIsNamesOnly(me.InputRule)
+}
+
+func (me *sampledRule) String() string {
+	return describeRule(me.InputRule)
+}
+
+// Returns the fraction of messages to keep from the Logger and the matched rule.
+func (l loggerCore) combinedSampleRate(match ruleMatch) float64 {
+	rate := 1.0
+	if l.sampleRate > 0 {
+		rate = l.sampleRate
+	}
+	if sr, ok := ruleAs[*sampledRule](match.rule); ok {
+		rate *= sr.rate
+	}
+	return rate
+}
+
+// Decides whether to keep the nth message from names with the given key.
+func keepSample(rate float64, key, n uint64) bool {
+	seed := samplingSeed.Load()
+	if seed == nil {
+		return rand.Float64() < rate
+	}
+	// Scale the top 53 bits of the mixed value to [0, 1) as rand.Float64 does.
+	return float64(splitMix64(*seed^key^n*0x9e3779b97f4a7c15)>>11)/(1<<53) < rate
+}
+
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"hash/fnv"
+	"slices"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Tracks use of a permutation of names. The names are immutable.
+type namesSeen struct {
+	names  []string
+	origin Origin
+	// The filter level of the first Logger to use the names. The variable is kept rather than its
+	// value, so the level follows changes such as by the admin socket.
+	filterLevel    Level
+	filterLevelVar *LevelVar
+	// A hash of the names that is stable between runs, used for deterministic sampling.
+	key  uint64
+	hits atomic.Uint64
+	// Unix nanoseconds.
+	lastHandled atomic.Int64
+}
+
+// Records a message being logged, including if it's then filtered, and returns how many have been
+// now. This is on the path of filtered messages, so it doesn't get the time.
+func (me *namesSeen) hit() uint64 {
+	return me.hits.Add(1)
+}
+
+// Records the time of a message passing filtering.
+func (me *namesSeen) handled() {
+	me.lastHandled.Store(time.Now().UnixNano())
+}
+
+// Returns the filter level currently in effect for the first Logger to use the names.
+func (me *namesSeen) currentFilterLevel() Level {
+	if me.filterLevelVar != nil {
+		return me.filterLevelVar.Level()
+	}
+	return me.filterLevel
+}
+
+func hashNames(names []string) uint64 {
+	h := fnv.New64a()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// A permutation of names that messages have been logged with.
+type SeenNames struct {
+	// All the names, as they're given to rules.
+	Names []string
+	// The structured form of Names.
+	Origin
+	// The number of messages logged, including those that were filtered.
+	Hits uint64
+	// When a message last passed filtering and was handled. This is the zero Time if every message
+	// was filtered, or the names were only checked with EnabledAt.
+	LastHandled time.Time
+	// The minimum level required to log a message with these names, as determined by the current
+	// rules, or the filter level of the first Logger to use these names.
+	Level Level
+}
+
+// Returns every permutation of names that has been seen by filtering, sorted by names. This can be
+// used to discover what is logging in a program, and to craft rules for it.
+func ListSeenNames() (ret []SeenNames) {
+	reportedNames.mu.Lock()
+	var all []*namesSeen
+	var walk func(*nameToAny)
+	walk = func(node *nameToAny) {
+		if node.emptyCase != nil {
+			all = append(all, node.emptyCase)
+		}
+		for _, child := range node.children {
+			walk(child)
+		}
+	}
+	walk(&reportedNames.base)
+	reportedNames.mu.Unlock()
+	rs := loadRuleSet()
+	for _, seen := range all {
+		sn := SeenNames{
+			Names:  slices.Clone(seen.names),
+			Origin: seen.origin,
+			Hits:   seen.hits.Load(),
+			Level:  seen.currentFilterLevel(),
+		}
+		sn.LoggerNames = slices.Clone(sn.LoggerNames)
+		if lastHandled := seen.lastHandled.Load(); lastHandled != 0 {
+			sn.LastHandled = time.Unix(0, lastHandled)
+		}
+		if index, level := rs.match(RuleInput{Names: seen.names, Origin: seen.origin}); index >= 0 {
+			sn.Level = level
+		}
+		ret = append(ret, sn)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return slices.Compare(ret[i].Names, ret[j].Names) < 0
+	})
+	return
+}
@@ -3,6 +3,7 @@ package log
 import (
 	"context"
 	"log/slog"
+	"runtime"
 
 	g "github.com/anacrolix/generics"
 )
@@ -16,16 +17,34 @@ type slogHandler struct {
 var _ slog.Handler = slogHandler{}
 
 func (s slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	// See IsEnabledFor for reasons why we probably should just return true here.
-	return s.l.IsEnabledFor(fromSlogLevel(level))
+	// slog doesn't tell us where the check was made from, so find the first caller outside slog.
+	// That's the same program counter slog puts in the Record, if it goes on to log.
+	return s.l.enabledAtPc(fromSlogLevel(level), slogCallerPc(1))
+}
+
+// Returns the program counter of the first frame outside log/slog, skipping skip frames above the
+// caller.
+func slogCallerPc(skip int) uintptr {
+	var pcs [8]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	for _, pc := range pcs[:n] {
+		if getPcLoc(pc).Package != "log/slog" {
+			return pc
+		}
+	}
+	return 0
 }
 
 func (s slogHandler) Handle(ctx context.Context, record slog.Record) error {
-	if len(s.attrs) > 0 {
+	level, exact := fromSlogLevelExact(record.Level)
+	if len(s.attrs) > 0 || !exact {
 		record = record.Clone()
 		record.AddAttrs(s.attrs...)
 	}
-	s.l.LazyLog(fromSlogLevel(record.Level), func() Msg { return Msg{slogMsg{record}} })
+	if !exact {
+		record.AddAttrs(slog.Any(SlogLevelKey, record.Level))
+	}
+	s.l.LazyLog(level, func() Msg { return Msg{slogMsg{record}} })
 	return nil
 }
 
@@ -5,62 +5,76 @@ import (
 	"math"
 )
 
-// Returns false if the level doesn't convert perfectly.
+// The key of the value added to messages from log/slog that have a slog.Level without an exact
+// equivalent Level. The value is the original slog.Level, which is used again if the message is
+// handled by SlogHandlerAsHandler, so the level survives the round trip.
+const SlogLevelKey = "slog_level"
+
+// Returns false if the level doesn't convert perfectly. Levels that aren't registered map to the
+// slog level of the closest registered level below them.
 func toSlogLevel(level Level) (slog.Level, bool) {
 	switch level {
 	case Never:
 		return slog.LevelDebug - 1, false
 	case NotSet:
 		return slog.LevelWarn - 1, false
-	case Debug:
-		return slog.LevelDebug, true
-	case Info:
-		return slog.LevelInfo, true
-	case Warning:
-		return slog.LevelWarn, true
-	case Error:
-		return slog.LevelError, true
-	case Critical:
-		return slog.LevelError + 1, true
 	case Disabled:
 		return slog.LevelDebug - 1, false
-	default:
-		panic(level)
 	}
+	var below *registeredLevel
+	levels := loadLevels().levels
+	for i := range levels {
+		rl := &levels[i]
+		if rl.level.isNotSet() {
+			continue
+		}
+		if rl.level.rank > level.rank {
+			if below == nil {
+				// Below all the registered levels.
+				return rl.def.SlogLevel - 1, false
+			}
+			break
+		}
+		below = rl
+	}
+	return below.def.SlogLevel, below.level == level
 }
 
 func toSlogMinLevel(level Level) slog.Level {
 	switch level {
-	case NotSet:
+	case Never, NotSet:
 		return math.MinInt
-	case Debug:
-		return slog.LevelDebug
-	case Info:
-		return slog.LevelInfo
-	case Warning:
-		return slog.LevelWarn
-	case Error:
-		return slog.LevelError
-	case Critical:
-		return slog.LevelError + 1
 	case Disabled:
 		return math.MaxInt
-	default:
-		panic(level)
 	}
+	sl, _ := toSlogLevel(level)
+	return sl
 }
 
 func fromSlogLevel(sl slog.Level) Level {
-	switch sl {
-	case slog.LevelDebug:
-		return Debug
-	case slog.LevelInfo:
-		return Info
-	case slog.LevelWarn:
-		return Warning
-	case slog.LevelError:
-		return Error
-	default:
-		panic(sl)
+	level, _ := fromSlogLevelExact(sl)
+	return level
+}
+
+// Returns the registered Level with the highest slog level that doesn't exceed sl, or the lowest
+// one if sl is below them all, and whether its slog level is sl exactly.
+func fromSlogLevelExact(sl slog.Level) (level Level, exact bool) {
+	var lowest, best *registeredLevel
+	levels := loadLevels().levels
+	for i := range levels {
+		rl := &levels[i]
+		if rl.level.isNotSet() {
+			continue
+		}
+		if lowest == nil || rl.def.SlogLevel < lowest.def.SlogLevel {
+			lowest = rl
+		}
+		if rl.def.SlogLevel <= sl && (best == nil || rl.def.SlogLevel > best.def.SlogLevel) {
+			best = rl
+		}
+	}
+	if best == nil {
+		best = lowest
 	}
+	return best.level, best.def.SlogLevel == sl
 }
@@ -8,12 +8,15 @@ import (
 )
 
 var (
-	gstbhLocker                 sync.Mutex
-	globalSlogTextBufferHandler = slogTextBufferHandler{}
+	gstbhLocker sync.Mutex
+	// Not left to an init function, since init may format records before later ones run.
+	globalSlogTextBufferHandler = newSlogTextBufferHandler()
 )
 
-func init() {
-	globalSlogTextBufferHandler.init()
+func newSlogTextBufferHandler() *slogTextBufferHandler {
+	me := new(slogTextBufferHandler)
+	me.init()
+	return me
 }
 
 func (me *slogTextBufferHandler) handleAppend(b []byte, r slog.Record) []byte {
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+)
+
+// The most frames captured for a stack trace.
+const maxStackTraceDepth = 32
+
+// The key of the stack trace in structured output, such as from SlogHandlerAsHandler.
+const StackTraceKey = "stack"
+
+// The program counters of the stack where a message was logged, innermost first. See
+// Logger.WithStackTraceLevel.
+type StackTrace []uintptr
+
+func (st StackTrace) Frames() *runtime.Frames {
+	return runtime.CallersFrames(st)
+}
+
+// Returns each frame as the function, and the file and line, like "pkg.Func path/file.go:12".
+func (st StackTrace) Strings() (ret []string) {
+	frames := st.Frames()
+	for {
+		f, more := frames.Next()
+		if f.PC != 0 {
+			ret = append(ret, fmt.Sprintf("%v %v:%v", f.Function, f.File, f.Line))
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// Appends the stack trace with each frame on its own line, beginning with indent.
+func appendStackTrace(b []byte, st StackTrace, indent string) []byte {
+	if len(st) == 0 {
+		return b
+	}
+	b = append(b, indent...)
+	b = append(b, "stack:\n"...)
+	for _, frame := range st.Strings() {
+		b = append(b, indent...)
+		b = append(b, "  "...)
+		b = append(b, frame...)
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// Returns whether messages at level get a stack trace.
+func (l loggerCore) capturesStackTraceAt(level Level) bool {
+	return !l.stackTraceLevel.isNotSet() && !level.LessThan(l.stackTraceLevel)
+}
+
+// Captures the stack of the caller of lazyLog, from the frame with logPc, which is where the message
+// was logged, up to maxStackTraceDepth frames. Starting from logPc drops frames between the logging
+// call and this package, such as those of log/slog for records from SlogHandler.
+func captureStackTrace(skip int, logPc uintptr) StackTrace {
+	// Leave room for the frames before logPc.
+	var pcs [2 * maxStackTraceDepth]uintptr
+	st := pcs[:runtime.Callers(skip+2, pcs[:])]
+	if i := slices.Index(st, logPc); i >= 0 {
+		st = st[i:]
+	}
+	if len(st) > maxStackTraceDepth {
+		st = st[:maxStackTraceDepth]
+	}
+	return StackTrace(slices.Clip(st))
+}
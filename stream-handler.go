@@ -18,7 +18,7 @@ func (me StreamHandler) Handle(r Record) {
 type ByteFormatter func(Record) []byte
 
 // Formats like:
-// [2023-12-02 14:49:32 +1100 NIL github.com/anacrolix/dht-indexer main.go:417]
+// [2023-12-02 14:49:32 +1100 NIL github.com/anacrolix/dht-indexer main main.go:417]
 //
 //	error maintaining search db: signal received: interrupt
 func twoLineFormatter(msg Record) []byte {
@@ -36,7 +36,8 @@ func twoLineFormatter(msg Record) []byte {
 	b = append(b, "]\n  "...)
 
 	b = appendRecordTextAndValues(b, msg)
-	return ensureTrailingNewline(b)
+	b = ensureTrailingNewline(b)
+	return appendStackTrace(b, msg.Stack, "  ")
 }
 
 func ensureTrailingNewline(b []byte) []byte {
@@ -67,7 +68,7 @@ func appendRecordTextAndValues(b []byte, msg Record) []byte {
 	return b
 }
 
-// Formats like: "[2023-12-02 14:34:02 +1100 INF] prefix: text [name name import-path short-file:line]"
+// Formats like: "[2023-12-02 14:34:02 +1100 INF] prefix: text [name name import-path function short-file:line]"
 func LineFormatter(msg Record) []byte {
 	b := []byte{'['}
 	beforeLen := len(b)
@@ -85,5 +86,6 @@ func LineFormatter(msg Record) []byte {
 		b = append(b, name...)
 	}
 	b = append(b, ']')
-	return ensureTrailingNewline(b)
+	b = ensureTrailingNewline(b)
+	return appendStackTrace(b, msg.Stack, "  ")
 }
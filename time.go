@@ -25,15 +25,15 @@ func GetDefaultTimeAppendFormatter() func([]byte) []byte {
 	}
 }
 
-var timeFmt string
-
-func init() {
-	var ok bool
-	timeFmt, ok = os.LookupEnv(EnvTimeFormat)
+// Read from EnvTimeFormat before init runs, so the invalid configuration logged by init is
+// timestamped with it.
+var timeFmt = func() string {
+	timeFmt, ok := os.LookupEnv(EnvTimeFormat)
 	if !ok {
 		timeFmt = "2006-01-02 15:04:05 -0700"
 	}
-}
+	return timeFmt
+}()
 
 var started = time.Now()
 
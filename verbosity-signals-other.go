@@ -0,0 +1,8 @@
+//go:build !unix
+
+package log
+
+// Installs handlers so that SIGUSR1 lowers the filter level of Default by one step, such as from
+// Warning to Info, and SIGUSR2 restores the level from before it was lowered. Each change is
+// logged. This has no effect on platforms without those signals, or after the first call.
+func InstallSignalHandlers() {}
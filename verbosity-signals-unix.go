@@ -0,0 +1,32 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var installSignalHandlers sync.Once
+
+// Installs handlers so that SIGUSR1 lowers the filter level of Default by one step, such as from
+// Warning to Info, and SIGUSR2 restores the level from before it was lowered. Each change is
+// logged. This has no effect on platforms without those signals, or after the first call.
+func InstallSignalHandlers() {
+	installSignalHandlers.Do(func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGUSR1, syscall.SIGUSR2)
+		go func() {
+			for sig := range c {
+				switch sig {
+				case syscall.SIGUSR1:
+					lowerDefaultFilterLevel()
+				case syscall.SIGUSR2:
+					restoreDefaultFilterLevel()
+				}
+			}
+		}()
+	})
+}
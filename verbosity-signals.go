@@ -0,0 +1,49 @@
+package log
+
+import (
+	"sync"
+)
+
+// Tracks changes to the Default filter level made by signals.
+var verbositySignals struct {
+	mu sync.Mutex
+	// The filter level before it was first lowered, if it has been.
+	restore *Level
+}
+
+// Lowers the Default filter level by one step, and logs the change.
+func lowerDefaultFilterLevel() {
+	verbositySignals.mu.Lock()
+	defer verbositySignals.mu.Unlock()
+	from := defaultFilterLevel.Level()
+	if verbositySignals.restore == nil {
+		verbositySignals.restore = &from
+	}
+	to := levelBelow(from)
+	if to == from {
+		return
+	}
+	defaultFilterLevel.Set(to)
+	logVerbosityChange(from, to)
+}
+
+// Restores the Default filter level from before it was lowered, and logs the change.
+func restoreDefaultFilterLevel() {
+	verbositySignals.mu.Lock()
+	defer verbositySignals.mu.Unlock()
+	if verbositySignals.restore == nil {
+		return
+	}
+	from := defaultFilterLevel.Level()
+	to := *verbositySignals.restore
+	verbositySignals.restore = nil
+	defaultFilterLevel.Set(to)
+	logVerbosityChange(from, to)
+}
+
+func logVerbosityChange(from, to Level) {
+	// Force the message through, so neither the filter level nor rules hide the change.
+	l := Default
+	l.forcedLevel = Warning
+	l.Levelf(Warning, "changed default filter level from %v to %v", from, to)
+}